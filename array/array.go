@@ -1,9 +1,11 @@
 package array
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
 	"strings"
@@ -12,11 +14,15 @@ import (
 var (
 	r1 *strings.Replacer
 	r2 *strings.Replacer
+	r3 *strings.Replacer
+	r4 *strings.Replacer
 )
 
 func init() {
 	r1 = strings.NewReplacer("~1", "/", "~0", "~")
 	r2 = strings.NewReplacer("~1", ".", "~0", "~")
+	r3 = strings.NewReplacer("~", "~0", ".", "~1")
+	r4 = strings.NewReplacer("~", "~0", "/", "~1")
 }
 
 // format JSONPointer to Slice
@@ -26,7 +32,12 @@ func JSONPointerToSlice(path string) ([]string, error) {
 	}
 
 	if path[0] != '/' {
-		return nil, errors.New("failed to resolve JSON pointer: path must begin with '/'")
+		return nil, &PathError{
+			Op:      "parse",
+			Path:    path,
+			Segment: -1,
+			Err:     fmt.Errorf("%w: json pointer must begin with '/'", ErrInvalidPath),
+		}
 	}
 
 	if path == "/" {
@@ -41,19 +52,46 @@ func JSONPointerToSlice(path string) ([]string, error) {
 	return hierarchy, nil
 }
 
-// format Path with KeyDelim to Slice
+// format Slice to JSONPointer
+func SliceToJSONPointer(path []string) string {
+	var sb strings.Builder
+	for _, v := range path {
+		sb.WriteString("/")
+		sb.WriteString(r4.Replace(v))
+	}
+
+	return sb.String()
+}
+
+// format Path with KeyDelim to Slice, `~1` is the keyDelim and `~0` is `~`
 func KeyDelimPathToSlice(path, keyDelim string) []string {
+	r := r2
+	if keyDelim != "." {
+		r = strings.NewReplacer("~1", keyDelim, "~0", "~")
+	}
+
 	hierarchy := strings.Split(path, keyDelim)
 	for i, v := range hierarchy {
-		hierarchy[i] = r2.Replace(v)
+		hierarchy[i] = r.Replace(v)
 	}
 
 	return hierarchy
 }
 
-var (
-	ErrOutOfBounds = errors.New("out of bounds")
-)
+// format Slice to Path with KeyDelim, the reverse of KeyDelimPathToSlice
+func KeyDelimSliceToPath(path []string, keyDelim string) string {
+	r := r3
+	if keyDelim != "." {
+		r = strings.NewReplacer("~", "~0", keyDelim, "~1")
+	}
+
+	hierarchy := make([]string, len(path))
+	for i, v := range path {
+		hierarchy[i] = r.Replace(v)
+	}
+
+	return strings.Join(hierarchy, keyDelim)
+}
 
 /**
  * 获取数组数据 / array struct
@@ -62,38 +100,80 @@ var (
  * @author deatil
  */
 type Array struct {
-	// 分隔符 / key Delim
-	keyDelim string
+	// 设置 / config
+	config
+
+	// 数据不存在 / the searched key is missing
+	missing bool
+
+	// 父级数据 / parent of the derived Array
+	parent *Array
+
+	// 相对父级的路径 / path from the parent
+	path []string
 
 	// 原始数据 / source data
 	source any
 }
 
-// New Array
-func New(source any) *Array {
-	return &Array{
-		keyDelim: ".",
-		source:   source,
+// 创建数据，派生的数据继承设置
+// New returns an Array of source, the options are inherited by every
+// Array derived from it
+func New(source any, opts ...Option) *Array {
+	arr := &Array{
+		config: defaultConfig(),
+		source: source,
 	}
+
+	for _, opt := range opts {
+		opt(&arr.config)
+	}
+
+	return arr
 }
 
 // 解析 JSON 数据
-// parse json data
-func ParseJSON(source []byte) (*Array, error) {
+// parse json data, numbers are decoded with WithNumberMode
+func ParseJSON(source []byte, opts ...Option) (*Array, error) {
+	arr := New(nil, opts...)
+
 	var dst any
-	err := json.Unmarshal(source, &dst)
+	var err error
+
+	if arr.numberMode == NumberFloat64 {
+		err = json.Unmarshal(source, &dst)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(source))
+		decoder.UseNumber()
+
+		if err = decoder.Decode(&dst); err == nil {
+			if _, tokenErr := decoder.Token(); tokenErr != io.EOF {
+				dst, err = nil, errors.New("invalid character after top-level value")
+			}
+		}
+	}
 
-	return New(dst), err
+	arr.source = arr.convertNumbers(dst)
+
+	return arr, err
 }
 
-// ParseJSONDecoder applies a json.Decoder to a *Container.
-func ParseJSONDecoder(decoder *json.Decoder) (*Array, error) {
+// ParseJSONDecoder applies a json.Decoder to a *Container. UseNumber is
+// called on the decoder when WithNumberMode is not NumberFloat64.
+func ParseJSONDecoder(decoder *json.Decoder, opts ...Option) (*Array, error) {
+	arr := New(nil, opts...)
+	if arr.numberMode != NumberFloat64 {
+		decoder.UseNumber()
+	}
+
 	var dst any
 	if err := decoder.Decode(&dst); err != nil {
 		return nil, err
 	}
 
-	return New(dst), nil
+	arr.source = arr.convertNumbers(dst)
+
+	return arr, nil
 }
 
 // 设置 keyDelim
@@ -104,6 +184,24 @@ func (this *Array) WithKeyDelim(data string) *Array {
 	return this
 }
 
+// 设置严格转换
+// set strict conversion for typed getters like GetAs
+func (this *Array) WithStrict(strict bool) *Array {
+	this.strict = strict
+
+	return this
+}
+
+// 设置自动创建切片，Set 时下一个路径为索引时创建 []any，
+// 索引超出时扩展切片并填充零值
+// set auto slices, Set creates []any for the containers of index
+// segments and grows slices to the index, filling with zero values
+func (this *Array) WithAutoSlices(autoSlices bool) *Array {
+	this.autoSlices = autoSlices
+
+	return this
+}
+
 // String marshals an element to a JSON formatted string.
 func (this *Array) String() string {
 	return string(this.ToJSON())
@@ -118,7 +216,7 @@ func (this *Array) Value() any {
 // 返回 JSON 数据
 // return JSON data
 func (this *Array) ToJSON() []byte {
-	if data, err := json.Marshal(this.anyDataFormat(this.source)); err == nil {
+	if data, err := this.MarshalJSON(); err == nil {
 		return data
 	}
 
@@ -129,8 +227,10 @@ func (this *Array) ToJSON() []byte {
 // and indent string.
 func (this *Array) ToJSONIndent(prefix, indent string) []byte {
 	if this.source != nil {
-		if data, err := json.MarshalIndent(this.anyDataFormat(this.source), prefix, indent); err == nil {
-			return data
+		if source, err := this.anyJSONFormat(this.source); err == nil {
+			if data, err := json.MarshalIndent(source, prefix, indent); err == nil {
+				return data
+			}
 		}
 	}
 
@@ -140,7 +240,12 @@ func (this *Array) ToJSONIndent(prefix, indent string) []byte {
 // 返回 JSON 数据
 // return JSON data
 func (this *Array) MarshalJSON() ([]byte, error) {
-	return json.Marshal(this.anyDataFormat(this.source))
+	source, err := this.anyJSONFormat(this.source)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(source)
 }
 
 // 判断是否存在
@@ -192,6 +297,64 @@ func Find(source any, key string) any {
 	return New(source).Find(key)
 }
 
+// 查找数据，返回是否存在，值为 nil 时也存在
+// Lookup returns the value of key and if the key is found, a key with
+// nil value is found
+func (this *Array) Lookup(key string) (any, bool) {
+	arr := this.Sub(key)
+
+	return arr.Value(), arr.Found()
+}
+
+// 查找数据，返回是否存在
+// Lookup returns the value of key from source and if the key is found
+func Lookup(source any, key string) (any, bool) {
+	return New(source).Lookup(key)
+}
+
+// 判断搜索的数据是否存在
+// Found reports if the data of Sub, Search or Index is found
+func (this *Array) Found() bool {
+	return !this.missing
+}
+
+// 判断是否存在，值为 nil 时也存在
+// if key in source return true or false, a key with nil value exists
+func (this *Array) Has(key string) bool {
+	_, ok := this.Lookup(key)
+
+	return ok
+}
+
+// 判断是否存在，值为 nil 时也存在
+// if key in source return true or false, a key with nil value exists
+func Has(source any, key string) bool {
+	return New(source).Has(key)
+}
+
+// 获取数据，值为 nil 时不使用默认值
+// get data with key and can set default value, the default value is
+// only used when the key is missing
+func (this *Array) GetNullable(key string, defVal ...any) any {
+	data, ok := this.Lookup(key)
+	if ok {
+		return data
+	}
+
+	if len(defVal) > 0 {
+		return defVal[0]
+	}
+
+	return nil
+}
+
+// 获取数据，值为 nil 时不使用默认值
+// get data with key from source and can set default value, the default
+// value is only used when the key is missing
+func GetNullable(source any, key string, defVal ...any) any {
+	return New(source).GetNullable(key, defVal...)
+}
+
 // 获取数据
 // get data and return Array
 func (this *Array) JSONPointer(path string) (*Array, error) {
@@ -226,12 +389,16 @@ func Sub(source any, key string) *Array {
 // 搜索数据
 // Search data with key
 func (this *Array) Search(path ...string) *Array {
-	source := this.search(this.source, path...)
+	if this.exceedsMaxDepth(len(path)) {
+		return this.notFound()
+	}
 
-	return &Array{
-		keyDelim: this.keyDelim,
-		source:   source,
+	source, resolved, found := this.search(this.source, path...)
+	if !found {
+		return this.missingChild(append([]string{}, path...))
 	}
+
+	return this.child(source, resolved)
 }
 
 // 搜索数据
@@ -244,19 +411,14 @@ func Search(source any, path ...string) *Array {
 // Index attempts to find and return an element
 func (this *Array) Index(index int) *Array {
 	if array, ok := this.Value().([]any); ok {
-		if index >= len(array) {
-			return &Array{
-				keyDelim: this.keyDelim,
-				source:   nil,
-			}
+		index, ok := normalizeIndex(index, len(array))
+		if !ok {
+			return this.missingChild([]string{strconv.Itoa(index)})
 		}
 
 		source := array[index]
 
-		return &Array{
-			keyDelim: this.keyDelim,
-			source:   source,
-		}
+		return this.child(source, []string{strconv.Itoa(index)})
 	}
 
 	// 反射返回
@@ -265,26 +427,18 @@ func (this *Array) Index(index int) *Array {
 		sourceValue = sourceValue.Elem()
 	}
 
-	if sourceValue.Kind() == reflect.Slice {
-		if index >= sourceValue.Len() {
-			return &Array{
-				keyDelim: this.keyDelim,
-				source:   nil,
-			}
+	if sourceValue.Kind() == reflect.Slice || sourceValue.Kind() == reflect.Array {
+		index, ok := normalizeIndex(index, sourceValue.Len())
+		if !ok {
+			return this.missingChild([]string{strconv.Itoa(index)})
 		}
 
 		source := sourceValue.Index(index).Interface()
 
-		return &Array{
-			keyDelim: this.keyDelim,
-			source:   source,
-		}
+		return this.child(source, []string{strconv.Itoa(index)})
 	}
 
-	return &Array{
-		keyDelim: this.keyDelim,
-		source:   nil,
-	}
+	return this.missingChild([]string{strconv.Itoa(index)})
 }
 
 // 返回 slice 数据
@@ -298,10 +452,7 @@ func (this *Array) Children() []*Array {
 	if array, ok := source.([]any); ok {
 		children := make([]*Array, len(array))
 		for i := 0; i < len(array); i++ {
-			children[i] = &Array{
-				keyDelim: this.keyDelim,
-				source:   array[i],
-			}
+			children[i] = this.child(array[i], []string{strconv.Itoa(i)})
 		}
 
 		return children
@@ -309,11 +460,8 @@ func (this *Array) Children() []*Array {
 
 	if mmap, ok := source.(map[string]any); ok {
 		children := make([]*Array, 0, len(mmap))
-		for _, obj := range mmap {
-			children = append(children, &Array{
-				keyDelim: this.keyDelim,
-				source:   obj,
-			})
+		for name, obj := range mmap {
+			children = append(children, this.child(obj, []string{name}))
 		}
 
 		return children
@@ -331,10 +479,7 @@ func (this *Array) ChildrenMap() map[string]*Array {
 	if mmap, ok := source.(map[string]any); ok {
 		children := make(map[string]*Array, len(mmap))
 		for name, obj := range mmap {
-			children[name] = &Array{
-				keyDelim: this.keyDelim,
-				source:   obj,
-			}
+			children[name] = this.child(obj, []string{name})
 		}
 
 		return children
@@ -352,66 +497,115 @@ func (this *Array) SetKey(value any, key string) (*Array, error) {
 }
 
 // 设置数据
-// set data with path
+// set data with path, missing containers are created as map[string]any
+// or as []any for index segments when WithAutoSlices is set. The change
+// is written back to the parents of a derived Array.
 func (this *Array) Set(value any, path ...any) (*Array, error) {
+	return this.setPath(value, path...)
+}
+
+// 设置数据，派生数据在父级的当前数据上设置
+// set data with path, a derived Array sets the data on the current data
+// of its parents
+func (this *Array) setPath(value any, path ...any) (*Array, error) {
+	if this.exceedsMaxDepth(len(path)) {
+		return nil, this.pathError("set", path, this.maxDepth, ErrInvalidPath, "path exceeds max depth %d", this.maxDepth)
+	}
+
+	if this.parent != nil {
+		return this.setParentPath(value, path)
+	}
+
+	return this.setSourcePath(value, path)
+}
+
+// 设置自身数据
+// set data with path in the source of the Array
+func (this *Array) setSourcePath(value any, path []any) (*Array, error) {
 	if len(path) == 0 {
 		this.source = value
 		return this, nil
 	}
 
 	if this.source == nil {
-		this.source = map[string]any{}
+		this.source = this.newPathNode(path[0])
 	}
 
 	source := this.source
 
+	// 复制路径，追加后记录索引用于回写
+	// copy the path, appended indexes are recorded for writing back
+	path = append([]any(nil), path...)
+
 	for target := 0; target < len(path); target++ {
 		pathSeg := toString(path[target])
 
 		switch typedObj := source.(type) {
 		case map[string]any:
+			// 使用已有键名的原始写法
+			// write to the original spelling of an existing key
+			pathSeg, _ = this.lookupKey(typedObj, pathSeg)
+			path[target] = pathSeg
+
 			if target == len(path)-1 {
 				source = value
 				typedObj[pathSeg] = source
 			} else if source = typedObj[pathSeg]; source == nil {
-				typedObj[pathSeg] = map[string]any{}
+				typedObj[pathSeg] = this.newPathNode(path[target+1])
 				source = typedObj[pathSeg]
 			}
 		case []any:
 			if pathSeg == "-" {
-				if target < 1 {
-					return nil, errors.New("unable to append new array index at root of path")
-				}
-
 				if target == len(path)-1 {
 					source = value
 				} else {
-					source = map[string]any{}
+					source = this.newPathNode(path[target+1])
 				}
 
 				typedObj = append(typedObj, source)
-				if _, err := this.Set(typedObj, path[:target]...); err != nil {
+				path[target] = len(typedObj) - 1
+
+				if target == 0 {
+					this.source = typedObj
+				} else if _, err := this.setPath(typedObj, path[:target]...); err != nil {
 					return nil, err
 				}
 			} else {
 				index, err := strconv.Atoi(pathSeg)
 				if err != nil {
-					return nil, fmt.Errorf("failed to resolve path segment '%v': found array but segment value '%v' could not be parsed into array index: %v", target, pathSeg, err)
+					return nil, this.pathError("set", path, target, ErrInvalidIndex, "'%v' is not an array index", pathSeg)
 				}
 
-				if index < 0 {
-					return nil, fmt.Errorf("failed to resolve path segment '%v': found array but index '%v' is invalid", target, pathSeg)
+				index, ok := normalizeIndex(index, len(typedObj))
+				if !ok && this.autoSlices && index >= len(typedObj) {
+					if index-len(typedObj) > maxSliceGrowth {
+						return nil, this.pathError("set", path, target, ErrOutOfBounds, "index '%v' is too far beyond array size %d", pathSeg, len(typedObj))
+					}
+
+					typedObj = append(typedObj, make([]any, index+1-len(typedObj))...)
+					if target == 0 {
+						this.source = typedObj
+					} else if _, err := this.setPath(typedObj, path[:target]...); err != nil {
+						return nil, err
+					}
+
+					ok = true
 				}
 
-				if len(typedObj) <= index {
-					return nil, fmt.Errorf("failed to resolve path segment '%v': found array but index '%v' exceeded target array size of '%v'", target, pathSeg, len(typedObj))
+				if !ok {
+					return nil, this.pathError("set", path, target, ErrOutOfBounds, "index '%v' exceeds array size %d", pathSeg, len(typedObj))
 				}
 
 				if target == len(path)-1 {
 					source = value
 					typedObj[index] = source
 				} else if source = typedObj[index]; source == nil {
-					return nil, fmt.Errorf("failed to resolve path segment '%v': field '%v' was not found", target, pathSeg)
+					if !this.autoSlices {
+						return nil, this.pathError("set", path, target, ErrNotFound, "")
+					}
+
+					source = this.newPathNode(path[target+1])
+					typedObj[index] = source
 				}
 			}
 		default:
@@ -424,101 +618,287 @@ func (this *Array) Set(value any, path ...any) (*Array, error) {
 			case sourceValue.Kind() == reflect.Map:
 				sourceType := sourceValue.Type()
 
-				pathSegValue, ok := this.convertTo(sourceType.Key(), path[target])
-				if !ok {
-					return nil, fmt.Errorf("convert failed to resolve path segment '%v': field '%v' was error", target, pathSeg)
+				if target < len(path)-1 && !isPathContainerType(sourceType.Elem()) {
+					return nil, this.pathError("set", path, target+1, ErrCollision, "%s is not a map or slice", sourceType.Elem())
+				}
+
+				// 空 map 创建后回写
+				// create the nil map and write it back
+				if sourceValue.IsNil() {
+					newMap := reflect.MakeMap(sourceType)
+
+					if sourceValue.CanSet() {
+						sourceValue.Set(newMap)
+					} else if target == 0 {
+						this.source = newMap.Interface()
+					} else if _, err := this.setPath(newMap.Interface(), path[:target]...); err != nil {
+						return nil, err
+					}
+
+					sourceValue = newMap
+				}
+
+				// 使用已有键名的原始写法
+				// write to the original spelling of an existing key
+				pathSegValue, found, err := this.resolveMapKey(sourceValue, path[target])
+				if found {
+					path[target] = mapKeyString(pathSegValue.Interface())
+				}
+
+				if err != nil {
+					return nil, this.pathError("set", path, target, err, "")
 				}
 
 				if target == len(path)-1 {
-					valueValue, ok := this.convertTo(sourceType.Elem(), value)
-					if !ok {
-						return nil, fmt.Errorf("map failed to resolve path segment '%v': field '%v' was error", target, pathSeg)
+					valueValue, err := this.convertTo(sourceType.Elem(), value)
+					if err != nil {
+						return nil, this.pathError("set", path, target, err, "")
 					}
 
 					sourceValue.SetMapIndex(pathSegValue, valueValue)
 
 					source = valueValue.Interface()
-				} else if source = sourceValue.MapIndex(pathSegValue).Interface(); source == nil {
-					valueValue := reflect.New(sourceType.Elem())
+				} else if mapValue := sourceValue.MapIndex(pathSegValue); !mapValue.IsValid() || isNilValue(mapValue) {
+					valueValue := this.newPathValue(sourceType.Elem(), path[target+1])
 
 					sourceValue.SetMapIndex(pathSegValue, valueValue)
 
 					source = valueValue.Interface()
+				} else {
+					source = mapValue.Interface()
 				}
-			case sourceValue.Kind() == reflect.Slice:
-				if pathSeg == "-" {
-					if target < 1 {
-						return nil, errors.New("unable to append new array index at root of path")
+			case (sourceValue.Kind() == reflect.Struct || sourceValue.Kind() == reflect.Array) && !sourceValue.CanAddr():
+				// 不可寻址的结构体和数组复制后回写
+				// copy the struct or array to set and write it back
+				copyValue := reflect.New(sourceValue.Type())
+				copyValue.Elem().Set(sourceValue)
+
+				res, err := this.derive(copyValue.Interface()).setPath(value, path[target:]...)
+				if err != nil {
+					var pathErr *PathError
+					if errors.As(err, &pathErr) {
+						return nil, this.pathError(pathErr.Op, path, target+pathErr.Segment, pathErr.Err, "")
 					}
 
-					if target == len(path)-1 {
-						source = value
-					} else {
-						source = map[string]any{}
+					return nil, err
+				}
+
+				if target == 0 {
+					this.source = copyValue.Elem().Interface()
+				} else if _, err := this.setPath(copyValue.Elem().Interface(), path[:target]...); err != nil {
+					return nil, err
+				}
+
+				return this.child(res.source, formatPathString(path)), nil
+			case sourceValue.Kind() == reflect.Struct:
+				field, ok := this.lookupField(sourceValue.Type(), pathSeg)
+				if !ok {
+					return nil, this.pathError("set", path, target, ErrNotFound, "field '%v' was not found", pathSeg)
+				}
+
+				fieldValue, ok := structFieldValue(sourceValue, field.index, true)
+				if !ok || !fieldValue.CanSet() {
+					return nil, this.pathError("set", path, target, ErrTypeMismatch, "field '%v' can not be set", pathSeg)
+				}
+
+				if target == len(path)-1 {
+					valueValue, err := this.convertTo(fieldValue.Type(), value)
+					if err != nil {
+						return nil, this.pathError("set", path, target, err, "")
 					}
 
-					valueValue, ok := this.convertTo(sourceValue.Type().Elem(), source)
-					if !ok {
-						return nil, fmt.Errorf("slice failed to resolve path segment '%v': field '%v' was error", target, pathSeg)
+					fieldValue.Set(valueValue)
+
+					source = fieldValue.Interface()
+				} else {
+					if isNilValue(fieldValue) {
+						fieldValue.Set(this.newPathValue(fieldValue.Type(), path[target+1]))
 					}
 
-					sourceValue = reflect.AppendSlice(sourceValue, valueValue)
+					if fieldValue.Kind() == reflect.Struct || fieldValue.Kind() == reflect.Array {
+						source = fieldValue.Addr().Interface()
+					} else {
+						source = fieldValue.Interface()
+					}
+				}
+			case sourceValue.Kind() == reflect.Slice:
+				if pathSeg == "-" {
+					var valueValue reflect.Value
+					if target == len(path)-1 {
+						var err error
+						valueValue, err = this.convertTo(sourceValue.Type().Elem(), value)
+						if err != nil {
+							return nil, this.pathError("set", path, target, err, "")
+						}
+					} else {
+						valueValue = this.newPathValue(sourceValue.Type().Elem(), path[target+1])
+					}
 
-					if _, err := this.Set(sourceValue, path[:target]...); err != nil {
+					source = valueValue.Interface()
+					path[target] = sourceValue.Len()
+
+					// 可设置时直接追加，否则回写
+					// append in place when settable, or write it back
+					if sourceValue.CanSet() {
+						sourceValue.Set(reflect.Append(sourceValue, valueValue))
+					} else if target == 0 {
+						this.source = reflect.Append(sourceValue, valueValue).Interface()
+					} else if _, err := this.setPath(reflect.Append(sourceValue, valueValue).Interface(), path[:target]...); err != nil {
 						return nil, err
 					}
 				} else {
 					index, err := strconv.Atoi(pathSeg)
 					if err != nil {
-						return nil, fmt.Errorf("slice failed to resolve path segment '%v': found array but segment value '%v' could not be parsed into array index: %v", target, pathSeg, err)
+						return nil, this.pathError("set", path, target, ErrInvalidIndex, "'%v' is not an array index", pathSeg)
 					}
 
-					if index < 0 {
-						return nil, fmt.Errorf("slice failed to resolve path segment '%v': found array but index '%v' is invalid", target, pathSeg)
+					index, ok := normalizeIndex(index, sourceValue.Len())
+					if !ok && this.autoSlices && index >= sourceValue.Len() {
+						if index-sourceValue.Len() > maxSliceGrowth {
+							return nil, this.pathError("set", path, target, ErrOutOfBounds, "index '%v' is too far beyond array size %d", pathSeg, sourceValue.Len())
+						}
+
+						size := index + 1 - sourceValue.Len()
+						grown := reflect.AppendSlice(sourceValue, reflect.MakeSlice(sourceValue.Type(), size, size))
+
+						// 可设置时直接扩展，否则回写
+						// grow in place when settable, or write it back
+						if sourceValue.CanSet() {
+							sourceValue.Set(grown)
+						} else if target == 0 {
+							this.source = grown.Interface()
+						} else if _, err := this.setPath(grown.Interface(), path[:target]...); err != nil {
+							return nil, err
+						}
+
+						sourceValue = grown
+						ok = true
 					}
 
-					if sourceValue.Len() <= index {
-						return nil, fmt.Errorf("slice failed to resolve path segment '%v': found array but index '%v' exceeded target array size of '%v'", target, pathSeg, sourceValue.Len())
+					if !ok {
+						return nil, this.pathError("set", path, target, ErrOutOfBounds, "index '%v' exceeds array size %d", pathSeg, sourceValue.Len())
 					}
 
 					if target == len(path)-1 {
 						source = value
 
-						valueValue, ok := this.convertTo(sourceValue.Index(index).Type(), source)
-						if !ok {
-							return nil, fmt.Errorf("slice failed to resolve path segment '%v': field '%v' was error", target, pathSeg)
+						valueValue, err := this.convertTo(sourceValue.Index(index).Type(), source)
+						if err != nil {
+							return nil, this.pathError("set", path, target, err, "")
 						}
 
 						sourceValue.Index(index).Set(valueValue)
-					} else if source = sourceValue.Index(index).Interface(); source == nil {
-						return nil, fmt.Errorf("slice failed to resolve path segment '%v': field '%v' was not found", target, pathSeg)
+					} else {
+						elemValue := sourceValue.Index(index)
+						if isNilValue(elemValue) {
+							if !this.autoSlices {
+								return nil, this.pathError("set", path, target, ErrNotFound, "")
+							}
+
+							elemValue.Set(this.newPathValue(elemValue.Type(), path[target+1]))
+						}
+
+						source = elemValue.Interface()
+					}
+				}
+			case sourceValue.Kind() == reflect.Array:
+				index, err := strconv.Atoi(pathSeg)
+				if err != nil {
+					if pathSeg == "-" {
+						return nil, this.pathError("set", path, target, ErrOutOfBounds, "can not append to %s", sourceValue.Type())
+					}
+
+					return nil, this.pathError("set", path, target, ErrInvalidIndex, "'%v' is not an array index", pathSeg)
+				}
+
+				index, ok := normalizeIndex(index, sourceValue.Len())
+				if !ok {
+					return nil, this.pathError("set", path, target, ErrOutOfBounds, "index '%v' exceeds array size %d", pathSeg, sourceValue.Len())
+				}
+
+				elemValue := sourceValue.Index(index)
+
+				if target == len(path)-1 {
+					valueValue, err := this.convertTo(elemValue.Type(), value)
+					if err != nil {
+						return nil, this.pathError("set", path, target, err, "")
+					}
+
+					elemValue.Set(valueValue)
+
+					source = elemValue.Interface()
+				} else {
+					if isNilValue(elemValue) {
+						if !this.autoSlices {
+							return nil, this.pathError("set", path, target, ErrNotFound, "")
+						}
+
+						elemValue.Set(this.newPathValue(elemValue.Type(), path[target+1]))
+					}
+
+					if elemValue.Kind() == reflect.Struct || elemValue.Kind() == reflect.Array {
+						source = elemValue.Addr().Interface()
+					} else {
+						source = elemValue.Interface()
 					}
 				}
 			default:
-				return nil, errors.New("encountered value collision whilst building path")
+				return nil, this.pathError("set", path, target, ErrCollision, "%T is not a map or slice", source)
 			}
 		}
 	}
 
-	return &Array{
-		keyDelim: this.keyDelim,
-		source:   source,
-	}, nil
+	return this.child(source, formatPathString(path)), nil
+}
+
+// 创建路径数据，自动创建切片时索引路径创建 []any
+// returns a new container for the path segment next, a []any for index
+// segments with auto slices or a map[string]any
+func (this *Array) newPathNode(next any) any {
+	if this.autoSlices && isIndexSegment(toString(next)) {
+		return []any{}
+	}
+
+	return map[string]any{}
+}
+
+// 创建指定类型的路径数据
+// returns a new path value of typ, interface types use newPathNode
+func (this *Array) newPathValue(typ reflect.Type, next any) reflect.Value {
+	if typ.Kind() == reflect.Interface {
+		return reflect.ValueOf(this.newPathNode(next))
+	}
+
+	return newPathValue(typ)
 }
 
 // SetIndex attempts to set a value of an array element based on an index.
 func (this *Array) SetIndex(value any, index int) (*Array, error) {
+	return this.setIndex(value, index)
+}
+
+func (this *Array) setIndex(value any, index int) (*Array, error) {
+	path := []any{index}
+
+	this.refresh()
+
 	if array, ok := this.Value().([]any); ok {
-		if index >= len(array) {
-			return nil, ErrOutOfBounds
+		index, ok := normalizeIndex(index, len(array))
+		if !ok {
+			return nil, this.pathError("set", path, 0, ErrOutOfBounds, "index %v exceeds array size %d", path[0], len(array))
+		}
+
+		if this.parent != nil {
+			if _, err := this.setPath(value, index); err != nil {
+				return nil, err
+			}
+
+			return this.derive(value), nil
 		}
 
 		array[index] = value
 
-		return &Array{
-			keyDelim: this.keyDelim,
-			source:   array[index],
-		}, nil
+		return this.derive(array[index]), nil
 	}
 
 	// 反射设置
@@ -527,25 +907,50 @@ func (this *Array) SetIndex(value any, index int) (*Array, error) {
 		sourceValue = sourceValue.Elem()
 	}
 
-	if sourceValue.Kind() == reflect.Slice {
-		if index >= sourceValue.Len() {
-			return nil, ErrOutOfBounds
+	if sourceValue.Kind() == reflect.Slice || sourceValue.Kind() == reflect.Array {
+		index, ok := normalizeIndex(index, sourceValue.Len())
+		if !ok {
+			return nil, this.pathError("set", path, 0, ErrOutOfBounds, "index %v exceeds array size %d", path[0], sourceValue.Len())
 		}
 
-		valueValue, ok := this.convertTo(sourceValue.Index(index).Type(), value)
-		if !ok {
-			return nil, fmt.Errorf("failed: field '%v' was error", value)
+		// 派生数据在父级的当前数据上设置
+		// a derived Array sets the value on the current data of its parents
+		if this.parent != nil {
+			if _, err := this.setPath(value, index); err != nil {
+				return nil, err
+			}
+
+			sourceValue = reflect.ValueOf(this.Value())
+			for sourceValue.Kind() == reflect.Ptr {
+				sourceValue = sourceValue.Elem()
+			}
+
+			return this.derive(sourceValue.Interface()), nil
+		}
+
+		valueValue, err := this.convertTo(sourceValue.Index(index).Type(), value)
+		if err != nil {
+			return nil, this.pathError("set", path, 0, err, "")
+		}
+
+		// 不可寻址的数组复制后设置
+		// copy the array to set when it can not be set
+		if sourceValue.Kind() == reflect.Array && !sourceValue.CanAddr() {
+			copyValue := reflect.New(sourceValue.Type()).Elem()
+			copyValue.Set(sourceValue)
+			copyValue.Index(index).Set(valueValue)
+
+			this.source = copyValue.Interface()
+
+			return this.derive(this.source), nil
 		}
 
 		sourceValue.Index(index).Set(valueValue)
 
-		return &Array{
-			keyDelim: this.keyDelim,
-			source:   sourceValue.Interface(),
-		}, nil
+		return this.derive(sourceValue.Interface()), nil
 	}
 
-	return nil, errors.New("not an array")
+	return nil, this.pathError("set", path, 0, ErrTypeMismatch, "%T is not an array", this.Value())
 }
 
 // ArrayOfSize creates a new array of a particular size at a path. Returns
@@ -581,53 +986,91 @@ func (this *Array) DeleteKey(key string) error {
 // 使用路径删除数据
 // delete data with path
 func (this *Array) Delete(path ...any) error {
-	if this == nil || this.source == nil {
-		return errors.New("source is nil")
+	return this.deletePath(path...)
+}
+
+// 删除数据，派生数据在父级的当前数据上删除
+// delete data with path, a derived Array deletes the data from the
+// current data of its parents
+func (this *Array) deletePath(path ...any) error {
+	// 空数据没有设置，使用默认分隔符
+	// a nil Array has no config, the default keyDelim is used
+	if this == nil {
+		return &PathError{
+			Op:      "delete",
+			Path:    KeyDelimSliceToPath(formatPathString(path), "."),
+			Segment: -1,
+			Err:     ErrNotFound,
+		}
 	}
 
 	if len(path) == 0 {
-		return errors.New("invalid search path")
+		return this.pathError("delete", path, -1, ErrInvalidPath, "empty path")
+	}
+
+	if this.exceedsMaxDepth(len(path)) {
+		return this.pathError("delete", path, this.maxDepth, ErrInvalidPath, "path exceeds max depth %d", this.maxDepth)
+	}
+
+	if this.parent != nil {
+		return this.deleteParentPath(path)
+	}
+
+	return this.deleteSourcePath(path)
+}
+
+// 删除自身数据
+// delete data with path from the source of the Array
+func (this *Array) deleteSourcePath(path []any) error {
+	if this.source == nil {
+		return this.pathError("delete", path, 0, ErrNotFound, "")
 	}
 
 	source := this.source
 
-	target := toString(path[len(path)-1])
+	// 最后一个路径索引
+	// index of the last path segment
+	last := len(path) - 1
+
+	target := toString(path[last])
 	if len(path) > 1 {
-		source = this.Search(formatPathString(path[:len(path)-1])...).Value()
+		parent, _, found := this.search(this.source, formatPathString(path[:last])...)
+		if !found {
+			return this.pathError("delete", path, this.missingSegment(formatPathString(path)), ErrNotFound, "")
+		}
+
+		source = parent
 	}
 
 	if obj, ok := source.(map[string]any); ok {
-		if _, ok = obj[target]; !ok {
-			return errors.New("field not found")
+		key, ok := this.lookupKey(obj, target)
+		if !ok {
+			return this.pathError("delete", path, last, ErrNotFound, "")
 		}
 
-		delete(obj, target)
+		delete(obj, key)
 
-		this.Set(obj, path[:len(path)-1]...)
-		return nil
+		_, err := this.setPath(obj, path[:last]...)
+		return err
 	}
 
 	if array, ok := source.([]any); ok {
-		if len(path) < 2 {
-			return errors.New("unable to delete array index at root of path")
-		}
-
 		index, err := strconv.Atoi(target)
 		if err != nil {
-			return fmt.Errorf("failed to parse array index '%v': %v", target, err)
+			return this.pathError("delete", path, last, ErrInvalidIndex, "'%v' is not an array index", target)
 		}
 
-		if index >= len(array) {
-			return ErrOutOfBounds
-		}
-		if index < 0 {
-			return ErrOutOfBounds
+		index, ok := normalizeIndex(index, len(array))
+		if !ok {
+			return this.pathError("delete", path, last, ErrOutOfBounds, "index '%v' exceeds array size %d", target, len(array))
 		}
 
-		array = append(array[:index], array[index+1:]...)
-		this.Set(array, path[:len(path)-1]...)
+		dst := make([]any, 0, len(array)-1)
+		dst = append(dst, array[:index]...)
+		dst = append(dst, array[index+1:]...)
 
-		return nil
+		_, err = this.setPath(dst, path[:last]...)
+		return err
 	}
 
 	// 通用删除
@@ -639,55 +1082,98 @@ func (this *Array) Delete(path ...any) error {
 	var dstValue reflect.Value
 
 	if sourceValue.Kind() == reflect.Map {
-		hasKey := false
-
-		dstValue = reflect.MakeMap(sourceValue.Type())
-
-		iter := sourceValue.MapRange()
-		for iter.Next() {
-			k := iter.Key().Interface()
-
-			if toString(k) != target {
-				dstValue.SetMapIndex(iter.Key(), iter.Value())
-			} else {
-				hasKey = true
+		key, found, err := this.resolveMapKey(sourceValue, path[last])
+		if !found {
+			if err != nil {
+				return this.pathError("delete", path, last, err, "")
 			}
-		}
 
-		if !hasKey {
-			return errors.New("field not found")
+			return this.pathError("delete", path, last, ErrNotFound, "")
 		}
 
-		this.Set(dstValue.Interface(), path[:len(path)-1]...)
+		// map 原地删除
+		// delete from the map in place
+		sourceValue.SetMapIndex(key, reflect.Value{})
+
 		return nil
 	}
 
 	if sourceValue.Kind() == reflect.Slice {
-		dstValue = reflect.MakeSlice(sourceValue.Type(), len(path)-1, len(path)-1)
+		index, err := strconv.Atoi(target)
+		if err != nil {
+			return this.pathError("delete", path, last, ErrInvalidIndex, "'%v' is not an array index", target)
+		}
 
-		if len(path) < 2 {
-			return errors.New("unable to delete array index at root of path")
+		index, ok := normalizeIndex(index, sourceValue.Len())
+		if !ok {
+			return this.pathError("delete", path, last, ErrOutOfBounds, "index '%v' exceeds array size %d", target, sourceValue.Len())
 		}
 
+		dstValue = reflect.MakeSlice(sourceValue.Type(), 0, sourceValue.Len()-1)
+		dstValue = reflect.AppendSlice(dstValue, sourceValue.Slice(0, index))
+		dstValue = reflect.AppendSlice(dstValue, sourceValue.Slice(index+1, sourceValue.Len()))
+
+		return this.writeBack(sourceValue, dstValue, formatPathString(path[:last]))
+	}
+
+	if sourceValue.Kind() == reflect.Array {
 		index, err := strconv.Atoi(target)
 		if err != nil {
-			return fmt.Errorf("failed to parse array index '%v': %v", target, err)
+			return this.pathError("delete", path, last, ErrInvalidIndex, "'%v' is not an array index", target)
 		}
 
-		if index >= sourceValue.Len() {
-			return ErrOutOfBounds
+		index, ok := normalizeIndex(index, sourceValue.Len())
+		if !ok {
+			return this.pathError("delete", path, last, ErrOutOfBounds, "index '%v' exceeds array size %d", target, sourceValue.Len())
 		}
-		if index < 0 {
-			return ErrOutOfBounds
+
+		if this.arrayDelete == ArrayDeleteError {
+			return this.pathError("delete", path, last, ErrTypeMismatch, "can not delete from %s", sourceValue.Type())
 		}
 
-		dstValue = reflect.AppendSlice(sourceValue.Slice(0, index), sourceValue.Slice(index+1, sourceValue.Len()))
+		// 固定长度数组的数据设为零值
+		// the element of a fixed array is zeroed
+		dstValue = reflect.New(sourceValue.Type()).Elem()
+		dstValue.Set(sourceValue)
+		dstValue.Index(index).Set(reflect.Zero(sourceValue.Type().Elem()))
+
+		return this.writeBack(sourceValue, dstValue, formatPathString(path[:last]))
+	}
+
+	if sourceValue.Kind() == reflect.Struct {
+		field, ok := this.lookupField(sourceValue.Type(), target)
+		if !ok {
+			return this.pathError("delete", path, last, ErrNotFound, "")
+		}
+
+		// 不可寻址时复制后回写
+		// copy the struct when it can not be set
+		dstValue = sourceValue
+		if !dstValue.CanAddr() {
+			dstValue = reflect.New(sourceValue.Type()).Elem()
+			dstValue.Set(sourceValue)
+		}
+
+		fieldValue, ok := structFieldValue(dstValue, field.index, false)
+		if !ok {
+			return this.pathError("delete", path, last, ErrNotFound, "")
+		}
+
+		if !fieldValue.CanSet() {
+			return this.pathError("delete", path, last, ErrTypeMismatch, "field '%v' can not be set", target)
+		}
+
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+
+		if !sourceValue.CanAddr() {
+			_, err := this.setPath(dstValue.Interface(), path[:last]...)
+			return err
+		}
 
-		this.Set(dstValue.Interface(), path[:len(path)-1]...)
 		return nil
 	}
 
-	return errors.New("source is error")
+	return this.pathError("delete", path, last, ErrCollision, "%T is not a map or slice", source)
 }
 
 // Flatten a array or slice into an source of key/value pairs for each
@@ -706,20 +1192,7 @@ func (this *Array) FlattenIncludeEmpty() (map[string]any, error) {
 }
 
 func (this *Array) flatten(includeEmpty bool) (map[string]any, error) {
-	flattened := map[string]any{}
-
-	source := this.anyDataFormat(this.source)
-
-	switch t := source.(type) {
-	case map[string]any:
-		this.walkObject("", t, flattened, includeEmpty)
-	case []any:
-		this.walkArray("", t, flattened, includeEmpty)
-	default:
-		return nil, errors.New("not a map or slice")
-	}
-
-	return flattened, nil
+	return this.FlattenWith(WithFlattenIncludeEmpty(includeEmpty))
 }
 
 // 获取 array, slice, map or string 的长度
@@ -756,17 +1229,17 @@ func (this *Array) IsArray() bool {
 	return this.IsSlice() || this.IsMap()
 }
 
-// 搜索
-// Search data with key from source
-func (this *Array) search(source any, path ...string) any {
-	var val any
-
+// 搜索，返回数据，实际路径以及数据是否存在。切片片段的数据为复制，
+// 实际路径为 nil
+// search data with path, returns the value, the resolved path and if the
+// path is found. The resolved path is nil when the value is copied by
+// a slice segment
+func (this *Array) search(source any, path ...string) (any, []string, bool) {
 	newSource, isMap := this.anyDataMapFormat(source)
 	if isMap {
 		// map
-		val = this.searchMap(newSource, path)
-		if val != nil {
-			return val
+		if val, resolved, ok := this.searchMap(newSource, path); ok {
+			return val, resolved, true
 		}
 	}
 
@@ -774,68 +1247,78 @@ func (this *Array) search(source any, path ...string) any {
 	source = this.anyDataFormat(source)
 
 	// 索引
-	val = this.searchIndexWithPathPrefixes(source, path)
-	if val != nil {
-		return val
+	if val, resolved, ok := this.searchIndexWithPathPrefixes(source, path); ok {
+		return val, resolved, true
 	}
 
-	return nil
+	return nil, nil, false
 }
 
 // 数组
 // searchMap
-func (this *Array) searchMap(source map[string]any, path []string) any {
+func (this *Array) searchMap(source map[string]any, path []string) (any, []string, bool) {
 	if len(path) == 0 {
-		return source
+		return source, []string{}, true
 	}
 
-	next, ok := source[path[0]]
+	key, ok := this.lookupKey(source, path[0])
 	if !ok {
-		return nil
+		return nil, nil, false
 	}
 
+	next := source[key]
+
 	if len(path) == 1 {
-		return next
+		return next, []string{key}, true
 	}
 
+	var val any
+	var resolved []string
+
 	switch n := next.(type) {
 	case map[any]any:
-		return this.searchMap(toStringMap(n), path[1:])
+		val, resolved, ok = this.searchMap(toStringMap(n), path[1:])
 	case map[string]any:
-		return this.searchMap(n, path[1:])
+		val, resolved, ok = this.searchMap(n, path[1:])
 	default:
-		if nextMap, isMap := this.anyMapFormat(next); isMap {
-			return this.searchMap(toStringMap(nextMap), path[1:])
+		nextMap, isMap := this.anyMapFormat(next)
+		if !isMap {
+			return nil, nil, false
 		}
+
+		val, resolved, ok = this.searchMap(toStringMap(nextMap), path[1:])
 	}
 
-	return nil
+	return val, prependPath(key, resolved), ok
 }
 
 // 索引查询
 // searchIndexWithPathPrefixes
-func (this *Array) searchIndexWithPathPrefixes(source any, path []string) any {
+func (this *Array) searchIndexWithPathPrefixes(source any, path []string) (any, []string, bool) {
 	if len(path) == 0 {
-		return source
+		return source, []string{}, true
 	}
 
 	for i := len(path); i > 0; i-- {
 		prefixKey := strings.Join(path[0:i], this.keyDelim)
 
 		var val any
+		var resolved []string
+		var ok bool
+
 		switch sourceIndexable := source.(type) {
 		case []any:
-			val = this.searchSliceWithPathPrefixes(sourceIndexable, prefixKey, i, path)
+			val, resolved, ok = this.searchSliceWithPathPrefixes(sourceIndexable, prefixKey, i, path)
 		case map[string]any:
-			val = this.searchMapWithPathPrefixes(sourceIndexable, prefixKey, i, path)
+			val, resolved, ok = this.searchMapWithPathPrefixes(sourceIndexable, prefixKey, i, path)
 		}
 
-		if val != nil {
-			return val
+		if ok {
+			return val, resolved, true
 		}
 	}
 
-	return nil
+	return nil, nil, false
 }
 
 // 切片
@@ -845,24 +1328,50 @@ func (this *Array) searchSliceWithPathPrefixes(
 	prefixKey string,
 	pathIndex int,
 	path []string,
-) any {
-	index, err := strconv.Atoi(prefixKey)
-	if err != nil || len(sourceSlice) <= index {
-		return nil
-	}
+) (any, []string, bool) {
+	var next any
+	var key string
+	var detached bool
+
+	if start, end, ok := parseSliceSegment(prefixKey, len(sourceSlice)); ok {
+		// 切片片段返回新数组，没有路径
+		// a slice segment returns a new array without path
+		next = append([]any{}, sourceSlice[start:end]...)
+		detached = true
+	} else {
+		index, err := strconv.Atoi(prefixKey)
+		if err != nil {
+			return nil, nil, false
+		}
+
+		index, ok = normalizeIndex(index, len(sourceSlice))
+		if !ok {
+			return nil, nil, false
+		}
 
-	next := sourceSlice[index]
+		next = sourceSlice[index]
+		key = strconv.Itoa(index)
+	}
 
 	if pathIndex == len(path) {
-		return next
+		if detached {
+			return next, nil, true
+		}
+
+		return next, []string{key}, true
 	}
 
 	n := this.anyDataFormat(next)
 	if n != nil {
-		return this.searchIndexWithPathPrefixes(n, path[pathIndex:])
+		val, resolved, ok := this.searchIndexWithPathPrefixes(n, path[pathIndex:])
+		if detached {
+			return val, nil, ok
+		}
+
+		return val, prependPath(key, resolved), ok
 	}
 
-	return nil
+	return nil, nil, false
 }
 
 // map 数据
@@ -872,29 +1381,32 @@ func (this *Array) searchMapWithPathPrefixes(
 	prefixKey string,
 	pathIndex int,
 	path []string,
-) any {
-	next, ok := sourceMap[prefixKey]
+) (any, []string, bool) {
+	key, ok := this.lookupKey(sourceMap, prefixKey)
 	if !ok {
-		return nil
+		return nil, nil, false
 	}
 
+	next := sourceMap[key]
+
 	if pathIndex == len(path) {
-		return next
+		return next, []string{key}, true
 	}
 
 	n := this.anyDataFormat(next)
 	if n != nil {
-		return this.searchIndexWithPathPrefixes(n, path[pathIndex:])
+		val, resolved, ok := this.searchIndexWithPathPrefixes(n, path[pathIndex:])
+		return val, prependPath(key, resolved), ok
 	}
 
-	return nil
+	return nil, nil, false
 }
 
 func (this *Array) isPathShadowedInDeepMap(path []string, m map[string]any) string {
 	var parentVal any
 
 	for i := 1; i < len(path); i++ {
-		parentVal = this.searchMap(m, path[0:i])
+		parentVal, _, _ = this.searchMap(m, path[0:i])
 		if parentVal == nil {
 			return ""
 		}
@@ -943,6 +1455,84 @@ func (this *Array) anyDataFormat(data any) any {
 	return nil
 }
 
+// any data 深度格式化，用于 JSON 输出，循环引用返回 ErrCycle
+// any data deep format for json marshal, cycles return ErrCycle
+func (this *Array) anyJSONFormat(data any) (any, error) {
+	return this.jsonFormat(data, cycleDetector{})
+}
+
+func (this *Array) jsonFormat(data any, seen cycleDetector) (any, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	if _, ok := data.([]byte); ok {
+		return data, nil
+	}
+
+	dataValue := reflect.ValueOf(data)
+	if isMarshalerType(dataValue.Type()) {
+		return data, nil
+	}
+
+	switch dataValue.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Pointer:
+		if dataValue.IsNil() {
+			return nil, nil
+		}
+	}
+
+	if !seen.enter(dataValue) {
+		return nil, cycleError(dataValue)
+	}
+	defer seen.leave(dataValue)
+
+	switch n := this.anyOutputFormat(data).(type) {
+	case map[string]any:
+		m := make(map[string]any, len(n))
+		for k, v := range n {
+			res, err := this.jsonFormat(v, seen)
+			if err != nil {
+				return nil, err
+			}
+
+			m[k] = res
+		}
+
+		return m, nil
+	case []any:
+		s := make([]any, len(n))
+		for k, v := range n {
+			res, err := this.jsonFormat(v, seen)
+			if err != nil {
+				return nil, err
+			}
+
+			s[k] = res
+		}
+
+		return s, nil
+	}
+
+	return data, nil
+}
+
+// any data 输出格式化，用于 JSON 和扁平化输出，结构体按照 omitempty 省略空字段
+// any data format for the output of ToJSON and Flatten, the empty struct
+// fields tagged with omitempty are omitted
+func (this *Array) anyOutputFormat(data any) any {
+	dataValue := reflect.ValueOf(data)
+	for dataValue.Kind() == reflect.Pointer && !dataValue.IsNil() {
+		dataValue = dataValue.Elem()
+	}
+
+	if isTraversableStruct(dataValue) {
+		return toStringMap(structToMap(dataValue, this.tagName, true))
+	}
+
+	return this.anyDataFormat(data)
+}
+
 // any data map 数据格式化
 // any data map format
 func (this *Array) anyDataMapFormat(data any) (map[string]any, bool) {
@@ -976,6 +1566,15 @@ func (this *Array) anyMapFormat(data any) (map[any]any, bool) {
 		dataValue = dataValue.Elem()
 	}
 
+	if !dataValue.IsValid() {
+		return m, isMap
+	}
+
+	// 结构体
+	if isTraversableStruct(dataValue) {
+		return structToMap(dataValue, this.tagName, false), true
+	}
+
 	// 获取最后的数据
 	newData := dataValue.Interface()
 
@@ -1010,6 +1609,10 @@ func (this *Array) anySliceFormat(data any) ([]any, bool) {
 		dataValue = dataValue.Elem()
 	}
 
+	if !dataValue.IsValid() {
+		return m, isSlice
+	}
+
 	// 获取最后的数据
 	newData := dataValue.Interface()
 
@@ -1030,57 +1633,153 @@ func (this *Array) anySliceFormat(data any) ([]any, bool) {
 	return m, isSlice
 }
 
-func (this *Array) walkObject(path string, obj, flat map[string]any, includeEmpty bool) {
-	if includeEmpty && len(obj) == 0 {
+func (this *Array) walkObject(path string, depth int, obj, flat map[string]any, opts *flattenOptions) error {
+	if opts.includeEmpty && len(obj) == 0 {
 		flat[path] = struct{}{}
 	}
 
-	for elePath, value := range obj {
-		if len(path) > 0 {
-			elePath = path + "." + elePath
-		}
-
-		v := this.anyDataFormat(value)
+	for key, value := range obj {
+		elePath := opts.joinKey(path, depth, key, false)
 
-		switch t := v.(type) {
-		case map[string]any:
-			this.walkObject(elePath, t, flat, includeEmpty)
-		case []any:
-			this.walkArray(elePath, t, flat, includeEmpty)
-		default:
-			flat[elePath] = value
+		if err := this.walkValue(elePath, depth+1, value, flat, opts); err != nil {
+			return err
 		}
 	}
+
+	return nil
 }
 
-func (this *Array) walkArray(path string, arr []any, flat map[string]any, includeEmpty bool) {
-	if includeEmpty && len(arr) == 0 {
+func (this *Array) walkArray(path string, depth int, arr []any, flat map[string]any, opts *flattenOptions) error {
+	if opts.includeEmpty && len(arr) == 0 {
 		flat[path] = []struct{}{}
 	}
 
 	for i, value := range arr {
-		elePath := strconv.Itoa(i)
-		if len(path) > 0 {
-			elePath = path + "." + elePath
+		elePath := opts.joinKey(path, depth, strconv.Itoa(i), true)
+
+		if err := this.walkValue(elePath, depth+1, value, flat, opts); err != nil {
+			return err
 		}
+	}
 
-		ele := this.anyDataFormat(value)
+	return nil
+}
 
-		switch t := ele.(type) {
-		case map[string]any:
-			this.walkObject(elePath, t, flat, includeEmpty)
-		case []any:
-			this.walkArray(elePath, t, flat, includeEmpty)
-		default:
-			flat[elePath] = value
+func (this *Array) walkValue(path string, depth int, value any, flat map[string]any, opts *flattenOptions) error {
+	if opts.maxDepth > 0 && depth >= opts.maxDepth {
+		flat[path] = value
+		return nil
+	}
+
+	valueValue := reflect.ValueOf(value)
+	if !opts.seen.enter(valueValue) {
+		return fmt.Errorf("%w: '%s'", cycleError(valueValue), path)
+	}
+	defer opts.seen.leave(valueValue)
+
+	switch t := this.anyOutputFormat(value).(type) {
+	case map[string]any:
+		return this.walkObject(path, depth, t, flat, opts)
+	case []any:
+		return this.walkArray(path, depth, t, flat, opts)
+	}
+
+	flat[path] = value
+	return nil
+}
+
+// 回写数据，可设置时直接设置
+// write the value back to path, set it in place when it can be set
+func (this *Array) writeBack(sourceValue, dstValue reflect.Value, path []string) error {
+	if sourceValue.CanSet() {
+		sourceValue.Set(dstValue)
+		return nil
+	}
+
+	_, err := this.setPath(dstValue.Interface(), formatPath(path)...)
+	return err
+}
+
+// 转换数据类型，开启 WithCoerceValues 时使用 Decode 的弱类型转换，
+// 数字转换检测溢出和丢失的小数
+// convert src to typ, WithCoerceValues converts like Decode with
+// WithWeaklyTypedInput, numbers fail on overflow and lost fractions
+func (this *Array) convertTo(typ reflect.Type, src any) (reflect.Value, error) {
+	if src == nil {
+		if !canBeNil(typ) {
+			return reflect.Value{}, fmt.Errorf("%w: null is not %s", ErrTypeMismatch, typ)
 		}
+
+		return reflect.Zero(typ), nil
+	}
+
+	if this.coerceValues {
+		return this.coerceTo(typ, src)
+	}
+
+	srcValue := reflect.ValueOf(src)
+
+	if isNumberKind(typ.Kind()) {
+		if num, ok := numberValue(srcValue, true); ok {
+			res, err := convertNumber(num, typ, true)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%w: %s", ErrTypeMismatch, err.Error())
+			}
+
+			return res, nil
+		}
+	}
+
+	// 数字不使用 Convert 转为字符串，避免 65 转为 "A"
+	// numbers are not converted to strings, Convert would turn 65 into "A"
+	if typ.Kind() == reflect.String && isNumberKind(srcValue.Kind()) {
+		return reflect.Value{}, fmt.Errorf("%w: value is not %s", ErrTypeMismatch, typ)
 	}
+
+	if !srcValue.CanConvert(typ) {
+		return reflect.Value{}, fmt.Errorf("%w: value is not %s", ErrTypeMismatch, typ)
+	}
+
+	return srcValue.Convert(typ), nil
 }
 
-func (this *Array) convertTo(typ reflect.Type, src any) (reflect.Value, bool) {
-	if !reflect.ValueOf(src).CanConvert(typ) {
-		return reflect.Value{}, false
+// 强制转换数据类型，数字转换检测溢出和丢失的小数
+// coerce src to typ, numbers are converted with overflow and fraction
+// detection
+func (this *Array) coerceTo(typ reflect.Type, src any) (reflect.Value, error) {
+	if reflect.TypeOf(src).AssignableTo(typ) {
+		return reflect.ValueOf(src), nil
+	}
+
+	d := &decoder{
+		arr: this,
+		opts: &decodeOptions{
+			tagName:      this.tagName,
+			weak:         true,
+			exactNumbers: true,
+		},
+	}
+
+	res := reflect.New(typ).Elem()
+	d.decode(nil, src, res)
+
+	switch {
+	case len(d.errs) == 0:
+		return res, nil
+	case len(d.errs) > 1:
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrTypeMismatch, (&DecodeError{d.errs}).Error())
+	}
+
+	// 数据本身的错误不带路径
+	// the error of the value itself has no path
+	err := d.errs[0]
+	if fieldErr, ok := err.(*DecodeFieldError); ok && fieldErr.Path == "" {
+		err = fieldErr.Err
+	}
+
+	if !errors.Is(err, ErrTypeMismatch) {
+		err = fmt.Errorf("%w: %s", ErrTypeMismatch, err.Error())
 	}
 
-	return reflect.ValueOf(src).Convert(typ), true
+	return reflect.Value{}, err
 }
@@ -3,6 +3,7 @@ package array
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -135,6 +136,17 @@ func Test_WithKeyDelim(t *testing.T) {
 
 }
 
+func Test_KeyDelimPathToSlice(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	assert(KeyDelimPathToSlice("a~1b.c~0d", "."), []string{"a.b", "c~d"}, "KeyDelimPathToSlice")
+	assert(KeyDelimPathToSlice("a~1b/c.d", "/"), []string{"a/b", "c.d"}, "KeyDelimPathToSlice keyDelim")
+
+	assert(KeyDelimSliceToPath([]string{"a.b", "c~d"}, "."), "a~1b.c~0d", "KeyDelimSliceToPath")
+	assert(KeyDelimSliceToPath([]string{"a.b", "c~d/e"}, "/"), "a.b/c~0d~1e", "KeyDelimSliceToPath keyDelim")
+	assert(KeyDelimPathToSlice(KeyDelimSliceToPath([]string{"a.b", "c~1d"}, "/"), "/"), []string{"a.b", "c~1d"}, "KeyDelimSliceToPath round trip")
+}
+
 func Test_Exists(t *testing.T) {
 	testData := []struct {
 		index string
@@ -914,8 +926,8 @@ func Test_Sub_And_ToJSON(t *testing.T) {
 		},
 		{
 			"b.hhTy3",
-			`null`,
-			"null",
+			`{"111":"hccccc","222":"hddddd","333":{"qq1":"qq1ccccc","qq2":"qq2ddddd","qq3":"qq3fffff"},"666":[12.3,32.5,22.56,789.156]}`,
+			"map[any]string in &map[int]any",
 		},
 	}
 
@@ -1003,8 +1015,8 @@ func Test_Sub_And_MarshalJSON(t *testing.T) {
 		},
 		{
 			"b.hhTy3",
-			``,
-			"null",
+			`{"111":"hccccc","222":"hddddd","333":{"qq1":"qq1ccccc","qq2":"qq2ddddd","qq3":"qq3fffff"},"666":[12.3,32.5,22.56,789.156]}`,
+			"map[any]string in &map[int]any",
 		},
 	}
 
@@ -1357,6 +1369,36 @@ func Test_SetMap(t *testing.T) {
 	}
 }
 
+func Test_SetTypedMapFailure(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	floats := map[float64]string{1.5: "x"}
+	arr := New(map[string]any{"f": floats})
+
+	_, err := arr.SetKey("y", "f.2.5")
+	assert(errors.Is(err, ErrCollision), true, "Set through scalar map value")
+	assert(floats, map[float64]string{1.5: "x"}, "Set through scalar map value keeps data")
+
+	data := map[string]any{"n": map[string]int(nil)}
+	if _, err := New(data).Set(1, "n", "a"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["n"], map[string]int{"a": 1}, "Set nil typed map in map")
+
+	var nilMap map[string]int
+	arr2 := New(nilMap)
+	if _, err := arr2.Set(1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr2.Value(), map[string]int{"a": 1}, "Set nil typed map")
+
+	ptr := &struct{ M map[string]int }{}
+	if _, err := New(ptr).Set(2, "M", "b"); err != nil {
+		t.Fatal(err)
+	}
+	assert(ptr.M, map[string]int{"b": 2}, "Set nil typed map field")
+}
+
 func Test_SetKey(t *testing.T) {
 	obj := New(arrData)
 	_, err := obj.SetKey("yyyyyyyyy", "b.ff.555")
@@ -1393,7 +1435,7 @@ func Test_ArraysTwo(t *testing.T) {
 		t.Error(err)
 	}
 
-	if _, err = test1.ArrayOfSizeIndex(2, 4); err != ErrOutOfBounds {
+	if _, err = test1.ArrayOfSizeIndex(2, 4); !errors.Is(err, ErrOutOfBounds) {
 		t.Errorf("Index should have been out of bounds")
 	}
 
@@ -1513,7 +1555,7 @@ func Test_BadIndexes(t *testing.T) {
 	oo := obj2.Sub("b.ddd")
 
 	_, err = oo.SetIndex(1000, 4)
-	if err != ErrOutOfBounds {
+	if !errors.Is(err, ErrOutOfBounds) {
 		t.Error("SetIndex error need ErrOutOfBounds")
 	}
 }
@@ -1954,3 +1996,297 @@ func Test_Example(t *testing.T) {
 func Example() {
 	Get(arrData, "b.hhTy3.666.3")
 }
+
+func Test_Lookup(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr, _ := ParseJSON([]byte(`{"a":null,"b":{"c":null,"d":[null,1]},"e.f":null}`))
+
+	testData := []struct {
+		key   string
+		value any
+		found bool
+	}{
+		{"a", nil, true},
+		{"b.c", nil, true},
+		{"b.d.0", nil, true},
+		{"b.d.1", float64(1), true},
+		{"b.d.2", nil, false},
+		{"b.d.-1", float64(1), true},
+		{"b.d.-3", nil, false},
+		{"e.f", nil, true},
+		{"x", nil, false},
+		{"a.x", nil, false},
+	}
+
+	for _, v := range testData {
+		value, found := arr.Lookup(v.key)
+
+		assert(value, v.value, "Lookup value "+v.key)
+		assert(found, v.found, "Lookup found "+v.key)
+		assert(arr.Has(v.key), v.found, "Has "+v.key)
+		assert(arr.Sub(v.key).Found(), v.found, "Found "+v.key)
+	}
+
+	assert(arr.Exists("a"), false, "Exists null")
+	assert(arr.Get("a", "def"), "def", "Get null")
+	assert(arr.GetNullable("a", "def"), nil, "GetNullable null")
+	assert(arr.GetNullable("x", "def"), "def", "GetNullable missing")
+
+	p, _ := arr.JSONPointer("/b/c")
+	assert(p.Found(), true, "JSONPointer null Found")
+
+	p, _ = arr.JSONPointer("/b/x")
+	assert(p.Found(), false, "JSONPointer missing Found")
+
+	assert(arr.Sub("b").Index(1).Found(), false, "Index map Found")
+	assert(arr.Sub("b.d").Index(0).Found(), true, "Index null Found")
+	assert(arr.Sub("b.d").Index(5).Found(), false, "Index out of range Found")
+
+	value, found := Lookup(map[string]any{"k": nil}, "k")
+	assert(value, nil, "Lookup func value")
+	assert(found, true, "Lookup func found")
+	assert(Has(map[string]any{"k": nil}, "k"), true, "Has func")
+	assert(GetNullable(map[string]any{"k": nil}, "k", 1), nil, "GetNullable func")
+	assert(New(nil).Found(), true, "New Found")
+}
+
+func Test_NegativeIndex(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr := New(map[string]any{
+		"items": []any{"a", "b", "c", "d"},
+		"ints":  []int{1, 2, 3},
+		"fixed": [3]string{"x", "y", "z"},
+	})
+
+	assert(arr.Get("items.-1"), "d", "Get items.-1")
+	assert(arr.Get("items.-4"), "a", "Get items.-4")
+	assert(arr.Get("items.-5"), nil, "Get items.-5")
+	assert(arr.Get("ints.-2"), 2, "Get ints.-2")
+	assert(arr.Get("fixed.-1"), "z", "Get fixed.-1")
+
+	assert(arr.Sub("items").Index(-2).Value(), "c", "Index -2")
+	assert(arr.Sub("ints").Index(-1).Value(), 3, "Index reflect -1")
+	assert(arr.Sub("fixed").Index(-3).Value(), "x", "Index array -3")
+	assert(arr.Sub("items").Index(-9).Found(), false, "Index -9")
+
+	assert(arr.Get("items.1:3"), []any{"b", "c"}, "Get items.1:3")
+	assert(arr.Get("items.:2"), []any{"a", "b"}, "Get items.:2")
+	assert(arr.Get("items.-2:"), []any{"c", "d"}, "Get items.-2:")
+	assert(arr.Get("items.3:1"), []any{}, "Get items.3:1")
+	assert(arr.Get("items.1:100"), []any{"b", "c", "d"}, "Get items.1:100")
+	assert(arr.Get("ints.0:2"), []any{1, 2}, "Get ints.0:2")
+	assert(arr.Get("fixed.1:"), []any{"y", "z"}, "Get fixed.1:")
+	assert(arr.Get("items.1:3.0"), "b", "Get items.1:3.0")
+	assert(arr.Get("items.a:b"), nil, "Get items.a:b")
+
+	if _, err := arr.Set("D", "items", "-1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("items.3"), "D", "Set items.-1")
+
+	if _, err := arr.Set(30, "ints", "-1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("ints"), []int{1, 2, 30}, "Set ints.-1")
+
+	if _, err := arr.Sub("items").SetIndex("A", -4); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("items.0"), "A", "SetIndex -4")
+
+	if _, err := arr.Sub("ints").SetIndex(10, -3); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("ints.0"), 10, "SetIndex reflect -3")
+
+	if err := arr.Delete("items", "-1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("items"), []any{"A", "b", "c"}, "Delete items.-1")
+
+	if err := arr.Delete("ints", "-2"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("ints"), []int{10, 30}, "Delete ints.-2")
+
+	_, err := arr.Set("x", "items", "-4")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Set items.-4 out of bounds")
+
+	err = arr.Delete("items", "-4")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Delete items.-4 out of bounds")
+}
+
+func Test_WithAutoSlices(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr := New(nil)
+	if _, err := arr.Set("x", "list", "0", "name"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Value(), map[string]any{"list": map[string]any{"0": map[string]any{"name": "x"}}}, "Set without auto slices")
+
+	arr = New(nil).WithAutoSlices(true)
+	if _, err := arr.Set("x", "list", "0", "name"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Value(), map[string]any{"list": []any{map[string]any{"name": "x"}}}, "Set list.0.name")
+
+	if _, err := arr.Set("y", "list", "2", "name"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("list"), []any{map[string]any{"name": "x"}, nil, map[string]any{"name": "y"}}, "Set list.2.name")
+
+	if _, err := arr.Set(1, "list", "1", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("list.1"), []any{1}, "Set list.1.0")
+
+	if _, err := arr.Set(2, "list", "-", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("list.3"), []any{2}, "Set list.-.0")
+
+	root := New(nil).WithAutoSlices(true)
+	if _, err := root.Set("a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(root.Value(), []any{nil, "a"}, "Set root 1")
+
+	typed := New(map[string]any{
+		"ints": []int{1},
+		"maps": []map[string]any{},
+	}).WithAutoSlices(true)
+
+	if _, err := typed.Set(3, "ints", "2"); err != nil {
+		t.Fatal(err)
+	}
+	assert(typed.Get("ints"), []int{1, 0, 3}, "Set ints.2")
+
+	if _, err := typed.Set("v", "maps", "1", "k"); err != nil {
+		t.Fatal(err)
+	}
+	assert(typed.Get("maps"), []map[string]any{nil, {"k": "v"}}, "Set maps.1.k")
+
+	ptr := &[]string{"a"}
+	if _, err := New(ptr).WithAutoSlices(true).Set("c", "2"); err != nil {
+		t.Fatal(err)
+	}
+	assert(*ptr, []string{"a", "", "c"}, "Set pointer slice")
+
+	sub := arr.Sub("list").WithAutoSlices(true)
+	if _, err := sub.Set("z", "0", "tags", "1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("list.0.tags"), []any{nil, "z"}, "Set sub list")
+
+	_, err := New([]any{}).Set(1, "3")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Set without auto slices out of bounds")
+
+	_, err = New([]any{}).WithAutoSlices(true).Set(1, "-3")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Set negative out of bounds")
+
+	_, err = New(nil, WithAutoSlices(true)).Set(1, "a", "999999999999999")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Set large index out of bounds")
+
+	_, err = New([]int{}, WithAutoSlices(true)).Set(1, "999999999999999")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Set large index typed out of bounds")
+
+	var pathErr *PathError
+	assert(errors.As(err, &pathErr), true, "Set large index PathError")
+}
+
+func Test_FixedArrays(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type Config struct {
+		Ports [2]int
+		Hosts *[2]string
+	}
+
+	hosts := [2]string{"a", "b"}
+	conf := &Config{Hosts: &hosts}
+
+	data := map[string]any{
+		"list": [3]any{1, map[string]any{"a": 1}, nil},
+		"conf": conf,
+		"copy": Config{},
+	}
+
+	arr := New(data)
+
+	if _, err := arr.Set(10, "list", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["list"], [3]any{10, map[string]any{"a": 1}, nil}, "Set map array")
+
+	if _, err := arr.Set(2, "list", "-2", "a"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("list.1.a"), 2, "Set nested in array")
+
+	if _, err := arr.Set(80, "conf", "Ports", "1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(conf.Ports, [2]int{0, 80}, "Set struct field array")
+
+	if _, err := arr.Set("c", "conf", "Hosts", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(hosts, [2]string{"c", "b"}, "Set pointer array")
+
+	if _, err := arr.Set(443, "copy", "Ports", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["copy"].(Config).Ports, [2]int{443, 0}, "Set copied struct array")
+
+	ptr := &[2]int{1, 2}
+	if _, err := New(ptr).SetIndex(3, -1); err != nil {
+		t.Fatal(err)
+	}
+	assert(*ptr, [2]int{1, 3}, "SetIndex pointer array")
+
+	sub := arr.Sub("list")
+	if _, err := sub.SetIndex("x", 2); err != nil {
+		t.Fatal(err)
+	}
+	assert(sub.Value(), [3]any{10, map[string]any{"a": 2}, "x"}, "SetIndex copied array")
+	assert(data["list"], [3]any{10, map[string]any{"a": 2}, "x"}, "SetIndex copied array write back")
+
+	if _, err := arr.Sub("copy.Ports").SetIndex(8080, 1); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["copy"].(Config).Ports, [2]int{443, 8080}, "SetIndex copied struct array write back")
+
+	_, err := arr.Set(1, "list", "3")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Set out of bounds")
+
+	_, err = arr.Set(1, "list", "-")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Set append")
+
+	_, err = arr.Set("s", "conf", "Ports", "0")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Set type mismatch")
+
+	if err := arr.Delete("list", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["list"], [3]any{nil, map[string]any{"a": 2}, "x"}, "Delete map array")
+
+	if err := arr.Delete("conf", "Hosts", "-1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(hosts, [2]string{"c", ""}, "Delete pointer array")
+
+	if err := New(ptr).Delete("0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(*ptr, [2]int{0, 3}, "Delete root pointer array")
+
+	err = New(data, WithArrayDeleteMode(ArrayDeleteError)).Delete("conf", "Ports", "1")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Delete error mode")
+	assert(conf.Ports, [2]int{0, 80}, "Delete error mode keeps value")
+
+	err = arr.Delete("list", "5")
+	assert(errors.Is(err, ErrOutOfBounds), true, "Delete out of bounds")
+}
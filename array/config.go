@@ -0,0 +1,206 @@
+package array
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// 数字解析模式
+// NumberMode sets how ParseJSON decodes numbers
+type NumberMode int
+
+const (
+	// 解析为 float64，和 encoding/json 一致
+	// NumberFloat64 decodes numbers as float64 like encoding/json
+	NumberFloat64 NumberMode = iota
+
+	// 解析为 json.Number
+	// NumberJSON decodes numbers as json.Number
+	NumberJSON
+
+	// 整数解析为 int64，其他为 float64
+	// NumberInt64 decodes integers as int64 and other numbers as float64
+	NumberInt64
+)
+
+// 固定长度数组的删除模式
+// ArrayDeleteMode sets how Delete removes elements of fixed size arrays
+type ArrayDeleteMode int
+
+const (
+	// 设为零值
+	// ArrayDeleteZero sets the element to its zero value
+	ArrayDeleteZero ArrayDeleteMode = iota
+
+	// 返回错误
+	// ArrayDeleteError returns an error wrapping ErrTypeMismatch
+	ArrayDeleteError
+)
+
+// 数据设置，派生的数据继承设置
+// config of an Array, it is inherited by every derived Array
+type config struct {
+	// 分隔符 / key Delim
+	keyDelim string
+
+	// 结构体标签 / struct tag name
+	tagName string
+
+	// 键名不区分大小写 / case-insensitive keys
+	caseInsensitive bool
+
+	// 键名格式化 / key normalizer
+	keyNormalizer KeyNormalizer
+
+	// 严格转换 / strict conversion of typed getters
+	strict bool
+
+	// 自动创建切片 / create slices for index segments in Set
+	autoSlices bool
+
+	// 强制转换数据 / coerce values to typed containers
+	coerceValues bool
+
+	// 数组删除模式 / delete mode of fixed arrays
+	arrayDelete ArrayDeleteMode
+
+	// 最大路径深度，0 为不限制 / max path depth, 0 is unlimited
+	maxDepth int
+
+	// 数字解析模式 / number mode of ParseJSON
+	numberMode NumberMode
+}
+
+// 默认设置
+// returns the default config
+func defaultConfig() config {
+	return config{
+		keyDelim: ".",
+		tagName:  defaultTagName,
+	}
+}
+
+// 设置
+// Option configures an Array of New
+type Option func(*config)
+
+// 设置分隔符，默认为 `.`
+// WithKeyDelim sets the key delimiter, the default is `.`
+func WithKeyDelim(keyDelim string) Option {
+	return func(conf *config) {
+		conf.keyDelim = keyDelim
+	}
+}
+
+// 设置结构体标签，默认为 json
+// WithTagName sets the struct tag name, the default is json
+func WithTagName(tagName string) Option {
+	return func(conf *config) {
+		conf.tagName = tagName
+	}
+}
+
+// 设置严格转换
+// WithStrict sets strict conversion for typed getters like GetAs
+func WithStrict(strict bool) Option {
+	return func(conf *config) {
+		conf.strict = strict
+	}
+}
+
+// 设置自动创建切片，索引超出切片长度过多时返回 ErrOutOfBounds
+// WithAutoSlices lets Set create []any for index segments and grow slices,
+// indexes too far beyond the slice length fail with ErrOutOfBounds
+func WithAutoSlices(autoSlices bool) Option {
+	return func(conf *config) {
+		conf.autoSlices = autoSlices
+	}
+}
+
+// 设置强制转换数据，写入带类型的容器时转换数据类型，如 "42" 转为 int，
+// "1s" 转为 time.Duration 以及 map[string]any 转为结构体
+// WithCoerceValues converts the values written to typed containers, like
+// "42" to int, "1s" to time.Duration and map[string]any to structs
+func WithCoerceValues(coerceValues bool) Option {
+	return func(conf *config) {
+		conf.coerceValues = coerceValues
+	}
+}
+
+// 设置固定长度数组的删除模式，默认设为零值
+// WithArrayDeleteMode sets how Delete removes elements of fixed size
+// arrays, the default is ArrayDeleteZero
+func WithArrayDeleteMode(mode ArrayDeleteMode) Option {
+	return func(conf *config) {
+		conf.arrayDelete = mode
+	}
+}
+
+// 设置最大路径深度，超出时查找不到数据，设置和删除返回错误
+// WithMaxDepth sets the max path depth, longer paths are not found by
+// searches and fail Set and Delete with ErrInvalidPath
+func WithMaxDepth(depth int) Option {
+	return func(conf *config) {
+		conf.maxDepth = depth
+	}
+}
+
+// 设置数字解析模式
+// WithNumberMode sets how ParseJSON and ParseJSONDecoder decode numbers
+func WithNumberMode(mode NumberMode) Option {
+	return func(conf *config) {
+		conf.numberMode = mode
+	}
+}
+
+// 创建继承设置的数据
+// returns a new Array of source with the config of this
+func (this *Array) derive(source any) *Array {
+	return &Array{
+		config: this.config,
+		source: source,
+	}
+}
+
+// 创建继承设置的不存在数据
+// returns a new missing Array with the config of this
+func (this *Array) notFound() *Array {
+	return &Array{
+		config:  this.config,
+		missing: true,
+	}
+}
+
+// 判断路径是否超出最大深度
+// if the path is longer than the max depth
+func (this *Array) exceedsMaxDepth(depth int) bool {
+	return this.maxDepth > 0 && depth > this.maxDepth
+}
+
+// 按照数字解析模式转换 json.Number
+// convert the json.Number values of data with the number mode
+func (this *Array) convertNumbers(data any) any {
+	if this.numberMode != NumberInt64 {
+		return data
+	}
+
+	switch d := data.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(d.String(), 10, 64); err == nil {
+			return i
+		}
+
+		f, _ := d.Float64()
+		return f
+	case map[string]any:
+		for k, v := range d {
+			d[k] = this.convertNumbers(v)
+		}
+	case []any:
+		for i, v := range d {
+			d[i] = this.convertNumbers(v)
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,256 @@
+package array
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_NewWithOptions(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr := New(map[string]any{
+		"a": map[string]any{
+			"b": []any{map[string]any{"c": "1"}},
+		},
+	}, WithKeyDelim("/"), WithStrict(true), WithAutoSlices(true))
+
+	assert(arr.Get("a/b/0/c"), "1", "Get with keyDelim")
+
+	derived := []*Array{
+		arr.Sub("a"),
+		arr.Search("a", "b"),
+		arr.Sub("a/b").Index(0),
+		arr.Sub("a/b").Children()[0],
+		arr.Sub("a").ChildrenMap()["b"],
+		arr.Sub("x"),
+	}
+
+	normalized, err := arr.Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived = append(derived, normalized)
+
+	res, err := arr.Set("2", "a", "b", "0", "d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived = append(derived, res)
+
+	for i, v := range derived {
+		assert(v.config, arr.config, "derived config "+toString(i))
+	}
+
+	_, err = arr.Sub("a/b/0").GetInt("c")
+	assert(errors.Is(err, ErrTypeMismatch), true, "derived strict")
+
+	if _, err := arr.Sub("a").Set("x", "list", "1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("a/list"), []any{nil, "x"}, "derived auto slices")
+}
+
+func Test_WithTagName(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type User struct {
+		Name string `yaml:"user_name"`
+		Age  int    `yaml:"user_age"`
+	}
+
+	arr := New(User{Name: "lily", Age: 18}, WithTagName("yaml"))
+
+	assert(arr.Get("user_name"), "lily", "Get yaml tag")
+	normalized, err := arr.Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(normalized.Value(), map[string]any{"user_name": "lily", "user_age": 18}, "Normalize yaml tag")
+
+	var user User
+	if err := New(map[string]any{"user_name": "tom"}, WithTagName("yaml")).Decode("", &user); err != nil {
+		t.Fatal(err)
+	}
+	assert(user.Name, "tom", "Decode yaml tag")
+
+	ptr := &User{}
+	if _, err := New(ptr, WithTagName("yaml")).Set(20, "user_age"); err != nil {
+		t.Fatal(err)
+	}
+	assert(ptr.Age, 20, "Set yaml tag")
+}
+
+func Test_WithMaxDepth(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr := New(map[string]any{
+		"a": map[string]any{"b": map[string]any{"c": 1}},
+	}, WithMaxDepth(2))
+
+	assert(arr.Get("a.b"), map[string]any{"c": 1}, "Get depth 2")
+	assert(arr.Exists("a.b.c"), false, "Exists depth 3")
+	assert(arr.Sub("a").Get("b.c"), 1, "Sub depth 2")
+
+	_, err := arr.Set(2, "a", "b", "d")
+	assert(errors.Is(err, ErrInvalidPath), true, "Set depth 3")
+
+	err = arr.Delete("a", "b", "c")
+	assert(errors.Is(err, ErrInvalidPath), true, "Delete depth 3")
+
+	if _, err := arr.Set(2, "a", "d"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("a.d"), 2, "Set depth 2")
+}
+
+func Test_WithNumberMode(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	data := []byte(`{"a": 1, "b": [2.5, 12345678901234567890], "c": 9007199254740993}`)
+
+	arr, err := ParseJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("a"), float64(1), "NumberFloat64")
+
+	arr, err = ParseJSON(data, WithNumberMode(NumberJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("c"), json.Number("9007199254740993"), "NumberJSON")
+
+	arr, err = ParseJSON(data, WithNumberMode(NumberInt64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("a"), int64(1), "NumberInt64 int")
+	assert(arr.Get("b.0"), 2.5, "NumberInt64 float")
+	assert(arr.Get("b.1"), float64(12345678901234567890), "NumberInt64 overflow")
+	assert(arr.Get("c"), int64(9007199254740993), "NumberInt64 large int")
+
+	_, err = ParseJSON([]byte(`{"a": 1} x`), WithNumberMode(NumberJSON))
+	assert(err != nil, true, "NumberJSON trailing data")
+
+	arr, err = ParseJSONDecoder(json.NewDecoder(strings.NewReader(`[1, 2]`)), WithNumberMode(NumberInt64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Value(), []any{int64(1), int64(2)}, "ParseJSONDecoder NumberInt64")
+}
+
+func Test_WithCoerceValues(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type Server struct {
+		Host string
+		Port int
+	}
+
+	ints := map[string]int{}
+	floats := []float64{0, 0}
+	int8s := []int8{0}
+	bools := []bool{false}
+	durations := map[string]time.Duration{}
+	servers := map[string]Server{}
+	serverList := []Server{{}}
+
+	data := map[string]any{
+		"ints":       ints,
+		"floats":     floats,
+		"int8s":      int8s,
+		"bools":      bools,
+		"durations":  durations,
+		"servers":    servers,
+		"serverList": serverList,
+	}
+
+	_, err := New(data).Set("42", "ints", "a")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Set without coercion")
+
+	arr := New(data, WithCoerceValues(true))
+
+	if _, err := arr.Set("42", "ints", "a"); err != nil {
+		t.Fatal(err)
+	}
+	assert(ints["a"], 42, "Set numeric string")
+
+	if _, err := arr.Set(int64(7), "ints", "b"); err != nil {
+		t.Fatal(err)
+	}
+	assert(ints["b"], 7, "Set int64 to int")
+
+	if _, err := arr.Sub("floats").SetIndex("1.5", 1); err != nil {
+		t.Fatal(err)
+	}
+	assert(floats[1], 1.5, "SetIndex numeric string")
+
+	if _, err := arr.Set(100, "int8s", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(int8s[0], int8(100), "Set narrowing")
+
+	_, err = arr.Set(300, "int8s", "0")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Set overflow")
+	assert(int8s[0], int8(100), "Set overflow keeps value")
+
+	if _, err := arr.Set("true", "bools", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assert(bools[0], true, "Set bool string")
+
+	if _, err := arr.Set("1m30s", "durations", "timeout"); err != nil {
+		t.Fatal(err)
+	}
+	assert(durations["timeout"], 90*time.Second, "Set duration string")
+
+	if _, err := arr.Set(map[string]any{"host": "localhost", "port": "8080"}, "servers", "main"); err != nil {
+		t.Fatal(err)
+	}
+	assert(servers["main"], Server{Host: "localhost", Port: 8080}, "Set map to struct")
+
+	if _, err := arr.Set("9090", "serverList", "0", "Port"); err != nil {
+		t.Fatal(err)
+	}
+	assert(serverList[0].Port, 9090, "Set struct field")
+
+	_, err = arr.Set("abc", "ints", "c")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Set invalid string")
+	assert(err.Error(), "set 'ints.c': segment 1: type mismatch: string 'abc' is not int", "Set invalid string message")
+
+	_, err = arr.Set(map[string]any{"port": "eighty"}, "servers", "backup")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Set invalid struct field")
+	assert(err.Error(), "set 'servers.backup': segment 1: 'Port': type mismatch: string 'eighty' is not int", "Set invalid struct field message")
+
+	_, err = arr.Sub("int8s").SetIndex(2.7, 0)
+	assert(errors.Is(err, ErrTypeMismatch), true, "SetIndex fraction")
+	assert(int8s[0], int8(100), "SetIndex fraction keeps value")
+
+	_, err = arr.Set(1.5, "durations", "short")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Set duration fraction")
+	assert(len(durations), 1, "Set duration fraction keeps map")
+
+	if _, err := arr.Set("2.0", "ints", "d"); err != nil {
+		t.Fatal(err)
+	}
+	assert(ints["d"], 2, "Set integral float string")
+
+	// =====
+
+	uint8s := []uint8{1}
+
+	_, err = New(uint8s).SetIndex(2.7, 0)
+	assert(errors.Is(err, ErrTypeMismatch), true, "SetIndex fraction without coercion")
+
+	_, err = New(uint8s).SetIndex(300, 0)
+	assert(errors.Is(err, ErrTypeMismatch), true, "SetIndex overflow without coercion")
+	assert(uint8s[0], uint8(1), "SetIndex without coercion keeps value")
+
+	if _, err := New(uint8s).SetIndex(2.0, 0); err != nil {
+		t.Fatal(err)
+	}
+	assert(uint8s[0], uint8(2), "SetIndex integral float without coercion")
+}
@@ -0,0 +1,319 @@
+package array
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// 字段解析错误
+// DecodeFieldError is the error of one field, Path is joined with keyDelim
+type DecodeFieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeFieldError) Error() string {
+	return fmt.Sprintf("'%s': %v", e.Path, e.Err)
+}
+
+func (e *DecodeFieldError) Unwrap() error {
+	return e.Err
+}
+
+// 解析错误
+// DecodeError collects every field error of Decode
+type DecodeError struct {
+	Errors []error
+}
+
+func (e *DecodeError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, "* "+err.Error())
+	}
+
+	return fmt.Sprintf("%d error(s) decoding:\n\n%s", len(e.Errors), strings.Join(msgs, "\n"))
+}
+
+func (e *DecodeError) Unwrap() []error {
+	return e.Errors
+}
+
+// 解析设置
+// decode options
+type decodeOptions struct {
+	tagName string
+	weak    bool
+
+	// 浮点数转为整数时不允许丢失小数
+	// floats can not lose their fractions as integers
+	exactNumbers bool
+}
+
+// 解析设置
+// DecodeOption configures Decode
+type DecodeOption func(*decodeOptions)
+
+// 设置结构体标签，默认为 Array 的结构体标签
+// WithDecodeTagName sets the struct tag name, the default is the tag name
+// of the Array
+func WithDecodeTagName(tagName string) DecodeOption {
+	return func(opts *decodeOptions) {
+		opts.tagName = tagName
+	}
+}
+
+// 弱类型转换，如 "5" 转为 int，"true" 转为 bool
+// WithWeaklyTypedInput converts weakly typed values, like "5" to int and
+// "true" to bool
+func WithWeaklyTypedInput() DecodeOption {
+	return func(opts *decodeOptions) {
+		opts.weak = true
+	}
+}
+
+// 解析数据到结构体
+// Decode maps the data of key onto out, out must be a pointer. Nothing
+// is changed when the key is not found, errors of all fields are
+// returned as *DecodeError.
+func (this *Array) Decode(key string, out any, opts ...DecodeOption) error {
+	options := &decodeOptions{
+		tagName: this.tagName,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Pointer || outValue.IsNil() {
+		return errors.New("out must be a non-nil pointer")
+	}
+
+	var path []string
+	data := this.source
+
+	if key != "" {
+		path = KeyDelimPathToSlice(key, this.keyDelim)
+		data = this.Find(key)
+	}
+
+	d := &decoder{
+		arr:  this,
+		opts: options,
+	}
+	d.decode(path, data, outValue.Elem())
+
+	if len(d.errs) > 0 {
+		return &DecodeError{d.errs}
+	}
+
+	return nil
+}
+
+// 解析数据到结构体
+// Decode maps the data of key from source onto out
+func Decode(source any, key string, out any, opts ...DecodeOption) error {
+	return New(source).Decode(key, out, opts...)
+}
+
+type decoder struct {
+	arr  *Array
+	opts *decodeOptions
+	errs []error
+}
+
+func (this *decoder) fail(path []string, err error) {
+	this.errs = append(this.errs, &DecodeFieldError{
+		Path: KeyDelimSliceToPath(path, this.arr.keyDelim),
+		Err:  err,
+	})
+}
+
+func (this *decoder) decode(path []string, data any, out reflect.Value) {
+	if data == nil {
+		return
+	}
+
+	if out.Kind() == reflect.Pointer {
+		if out.IsNil() {
+			out.Set(reflect.New(out.Type().Elem()))
+		}
+
+		this.decode(path, data, out.Elem())
+		return
+	}
+
+	dataValue := reflect.ValueOf(data)
+
+	if out.Type() == durationType {
+		if s, ok := data.(string); ok {
+			dur, err := time.ParseDuration(s)
+			if err != nil {
+				this.fail(path, err)
+				return
+			}
+
+			out.SetInt(int64(dur))
+			return
+		}
+	}
+
+	if s, ok := data.(string); ok && out.CanAddr() && out.Addr().Type().Implements(textUnmarshalerType) {
+		if err := out.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			this.fail(path, err)
+		}
+
+		return
+	}
+
+	switch out.Kind() {
+	case reflect.Interface:
+		if !dataValue.Type().AssignableTo(out.Type()) {
+			this.fail(path, fmt.Errorf("%w: %T is not %s", ErrTypeMismatch, data, out.Type()))
+			return
+		}
+
+		out.Set(dataValue)
+	case reflect.Struct:
+		this.decodeStruct(path, data, out)
+	case reflect.Map:
+		this.decodeMap(path, dataValue, out)
+	case reflect.Slice, reflect.Array:
+		this.decodeSlice(path, data, out)
+	default:
+		if this.opts.exactNumbers {
+			if err := checkExactNumber(data, out.Type()); err != nil {
+				this.fail(path, fmt.Errorf("%w: %s", ErrTypeMismatch, err.Error()))
+				return
+			}
+		}
+
+		res, err := convertAs(data, out.Type(), !this.opts.weak)
+		if err != nil {
+			this.fail(path, fmt.Errorf("%w: %s", ErrTypeMismatch, err.Error()))
+			return
+		}
+
+		out.Set(res)
+	}
+}
+
+func (this *decoder) decodeStruct(path []string, data any, out reflect.Value) {
+	if dataValue := reflect.ValueOf(data); dataValue.Type() == out.Type() {
+		out.Set(dataValue)
+		return
+	}
+
+	dataMap, ok := this.arr.anyDataMapFormat(data)
+	if !ok {
+		this.fail(path, fmt.Errorf("%w: %T is not a map", ErrTypeMismatch, data))
+		return
+	}
+
+	for _, f := range getDecodeStructFields(out.Type(), this.opts.tagName) {
+		value, ok := dataMap[f.name]
+		if !ok {
+			// 不区分大小写匹配
+			// match the key case-insensitively
+			for k, v := range dataMap {
+				if strings.EqualFold(k, f.name) {
+					value, ok = v, true
+					break
+				}
+			}
+		}
+
+		if !ok || value == nil {
+			continue
+		}
+
+		fieldValue, _ := structFieldValue(out, f.index, true)
+		if !fieldValue.CanSet() {
+			continue
+		}
+
+		this.decode(appendPath(path, f.name), value, fieldValue)
+	}
+}
+
+func (this *decoder) decodeMap(path []string, dataValue reflect.Value, out reflect.Value) {
+	for dataValue.Kind() == reflect.Pointer && !dataValue.IsNil() {
+		dataValue = dataValue.Elem()
+	}
+
+	if dataValue.Kind() != reflect.Map {
+		if dataMap, ok := this.arr.anyDataMapFormat(dataValue.Interface()); ok {
+			dataValue = reflect.ValueOf(dataMap)
+		} else {
+			this.fail(path, fmt.Errorf("%w: %s is not a map", ErrTypeMismatch, dataValue.Type()))
+			return
+		}
+	}
+
+	if out.IsNil() {
+		out.Set(reflect.MakeMapWithSize(out.Type(), dataValue.Len()))
+	}
+
+	keyType := out.Type().Key()
+	elemType := out.Type().Elem()
+
+	iter := dataValue.MapRange()
+	for iter.Next() {
+		keyPath := appendPath(path, toString(iter.Key().Interface()))
+
+		key, err := convertAs(iter.Key().Interface(), keyType, false)
+		if err != nil {
+			this.fail(keyPath, fmt.Errorf("%w: %s", ErrTypeMismatch, err.Error()))
+			continue
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if existing := out.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+
+		errCount := len(this.errs)
+
+		this.decode(keyPath, iter.Value().Interface(), elem)
+
+		if len(this.errs) == errCount {
+			out.SetMapIndex(key, elem)
+		}
+	}
+}
+
+func (this *decoder) decodeSlice(path []string, data any, out reflect.Value) {
+	dataSlice, ok := this.arr.anySliceFormat(data)
+	if !ok {
+		if !this.opts.weak {
+			this.fail(path, fmt.Errorf("%w: %T is not a slice", ErrTypeMismatch, data))
+			return
+		}
+
+		// 弱类型时单个数据转为切片
+		// a single value is a slice of one with weak typing
+		dataSlice = []any{data}
+	}
+
+	if out.Kind() == reflect.Array {
+		if len(dataSlice) > out.Len() {
+			this.fail(path, fmt.Errorf("%w: %d elements overflow %s", ErrOutOfBounds, len(dataSlice), out.Type()))
+			return
+		}
+	} else {
+		out.Set(reflect.MakeSlice(out.Type(), len(dataSlice), len(dataSlice)))
+	}
+
+	for i, v := range dataSlice {
+		this.decode(appendPath(path, toString(i)), v, out.Index(i))
+	}
+}
@@ -0,0 +1,161 @@
+package array
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDecodeBase struct {
+	ID      int    `json:"id"`
+	Created string `json:"created"`
+}
+
+type testDecodeHost struct {
+	Addr string `json:"addr"`
+	Port uint16 `json:"port"`
+}
+
+type testDecodeConfig struct {
+	testDecodeBase
+
+	Name    string                    `json:"name"`
+	Debug   bool                      `json:"debug"`
+	Timeout time.Duration             `json:"timeout"`
+	Start   time.Time                 `json:"start"`
+	Hosts   []testDecodeHost          `json:"hosts"`
+	Primary *testDecodeHost           `json:"primary"`
+	Labels  map[string]string         `json:"labels"`
+	Ports   map[int]bool              `json:"ports"`
+	Pair    [2]float64                `json:"pair"`
+	Extra   any                       `json:"extra"`
+	Tags    []string                  `json:"tags"`
+	Nested  map[string]testDecodeHost `json:"nested"`
+	Meta    testDecodeMeta            `json:"meta,squash"`
+}
+
+type testDecodeMeta struct {
+	Owner string `json:"owner"`
+}
+
+func Test_Decode(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr, _ := ParseJSON([]byte(`{
+		"db": {
+			"id": 7,
+			"created": "today",
+			"name": "main",
+			"debug": true,
+			"timeout": "1m30s",
+			"start": "2024-01-02T03:04:05Z",
+			"hosts": [{"addr": "a", "port": 1}, {"addr": "b", "port": 2}],
+			"primary": {"addr": "p", "port": 3},
+			"labels": {"env": "prod"},
+			"ports": {"80": true},
+			"pair": [1.5, 2.5],
+			"extra": {"x": 1},
+			"tags": ["t1"],
+			"nested": {"n": {"ADDR": "n"}},
+			"owner": "me"
+		}
+	}`))
+
+	var cfg testDecodeConfig
+	if err := arr.Decode("db", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(cfg.ID, 7, "Decode embedded")
+	assert(cfg.Created, "today", "Decode embedded string")
+	assert(cfg.Name, "main", "Decode string")
+	assert(cfg.Debug, true, "Decode bool")
+	assert(cfg.Timeout, 90*time.Second, "Decode duration")
+	assert(cfg.Start, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "Decode TextUnmarshaler")
+	assert(cfg.Hosts, []testDecodeHost{{"a", 1}, {"b", 2}}, "Decode slice of struct")
+	assert(cfg.Primary, &testDecodeHost{"p", 3}, "Decode pointer")
+	assert(cfg.Labels, map[string]string{"env": "prod"}, "Decode map")
+	assert(cfg.Ports, map[int]bool{80: true}, "Decode map key")
+	assert(cfg.Pair, [2]float64{1.5, 2.5}, "Decode array")
+	assert(cfg.Extra, map[string]any{"x": float64(1)}, "Decode interface")
+	assert(cfg.Tags, []string{"t1"}, "Decode string slice")
+	assert(cfg.Nested, map[string]testDecodeHost{"n": {Addr: "n"}}, "Decode case-insensitive")
+	assert(cfg.Meta.Owner, "me", "Decode squash")
+	assert(Get(cfg, "meta.owner"), "me", "squash is only used by Decode")
+	assert(Get(cfg, "owner"), nil, "squash is only used by Decode owner")
+
+	var host testDecodeHost
+	if err := Decode(map[string]any{"h": map[string]any{"addr": "x"}}, "h", &host); err != nil {
+		t.Fatal(err)
+	}
+	assert(host, testDecodeHost{Addr: "x"}, "Decode func")
+
+	if err := arr.Decode("db", cfg); err == nil {
+		t.Error("Decode should error on non-pointer")
+	}
+
+	var missing testDecodeHost
+	assert(arr.Decode("missing", &missing), nil, "Decode missing key")
+}
+
+func Test_Decode_Weak(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type weakConfig struct {
+		Port  int      `cfg:"port"`
+		Debug bool     `cfg:"debug"`
+		Name  string   `cfg:"name"`
+		Tags  []string `cfg:"tags"`
+	}
+
+	data := map[string]any{
+		"port":  "5",
+		"debug": "true",
+		"name":  12,
+		"tags":  "one",
+	}
+
+	var cfg weakConfig
+	if err := Decode(data, "", &cfg, WithDecodeTagName("cfg"), WithWeaklyTypedInput()); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(cfg, weakConfig{5, true, "12", []string{"one"}}, "Decode weak")
+
+	var strict weakConfig
+	err := Decode(data, "", &strict, WithDecodeTagName("cfg"))
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Decode error got %v", err)
+	}
+
+	assert(len(decodeErr.Errors), 4, "Decode collects all errors")
+
+	paths := make([]string, 0)
+	for _, e := range decodeErr.Errors {
+		paths = append(paths, e.(*DecodeFieldError).Path)
+	}
+
+	assert(strings.Join(paths, ","), "port,debug,name,tags", "Decode error paths")
+}
+
+func Test_Decode_ErrorPath(t *testing.T) {
+	assert := assertT(t)
+
+	arr, _ := ParseJSON([]byte(`{"db":{"hosts":[{"port":1},{"port":"x"}],"timeout":"soon"}}`))
+
+	var cfg testDecodeConfig
+	err := arr.Decode("db", &cfg)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Decode error got %v", err)
+	}
+
+	assert(len(decodeErr.Errors), "2", "Decode error count")
+	assert(decodeErr.Errors[0].(*DecodeFieldError).Path, "db.timeout", "Decode error path")
+	assert(decodeErr.Errors[1].(*DecodeFieldError).Path, "db.hosts.1.port", "Decode nested error path")
+	assert(cfg.Hosts[0].Port, "1", "Decode keeps valid fields")
+}
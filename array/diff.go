@@ -0,0 +1,249 @@
+package array
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// 差异操作
+// DiffOperation is a change between two Arrays, Path is a JSON Pointer
+type DiffOperation struct {
+	Op       string
+	Path     string
+	OldValue any
+	NewValue any
+}
+
+// 差异操作列表
+// DiffOperations is the list of changes between two Arrays
+type DiffOperations []DiffOperation
+
+// 转为 JSON Patch 操作
+// PatchOperations returns the changes as RFC 6902 operations
+func (this DiffOperations) PatchOperations() []PatchOperation {
+	ops := make([]PatchOperation, 0, len(this))
+	for _, op := range this {
+		patchOp := PatchOperation{
+			Op:   op.Op,
+			Path: op.Path,
+		}
+
+		if op.Op != "remove" {
+			patchOp.Value = op.NewValue
+		}
+
+		ops = append(ops, patchOp)
+	}
+
+	return ops
+}
+
+// 转为 JSON Patch 文档
+// ToJSONPatch returns the changes as a RFC 6902 JSON Patch document
+func (this DiffOperations) ToJSONPatch() ([]byte, error) {
+	return json.Marshal(this.PatchOperations())
+}
+
+// 差异设置
+// diff options
+type diffOptions struct {
+	lcs bool
+}
+
+// 差异设置
+// DiffOption configures Diff
+type DiffOption func(*diffOptions)
+
+// 使用 LCS 比较数组
+// WithDiffLCS compares arrays by longest common subsequence, the default
+// is comparing by index
+func WithDiffLCS() DiffOption {
+	return func(opts *diffOptions) {
+		opts.lcs = true
+	}
+}
+
+// 比较差异
+// Diff returns the changes to turn a into b
+func Diff(a, b *Array, opts ...DiffOption) DiffOperations {
+	options := &diffOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	d := &differ{
+		arr:   a,
+		opts:  options,
+		ops:   make(DiffOperations, 0),
+		seenA: cycleDetector{},
+		seenB: cycleDetector{},
+	}
+	d.diff(nil, a.Value(), b.Value())
+
+	return d.ops
+}
+
+type differ struct {
+	arr  *Array
+	opts *diffOptions
+	ops  DiffOperations
+
+	// 当前路径上的数据，循环引用不再展开比较
+	// the data on the current path, cycles are compared as values
+	seenA cycleDetector
+	seenB cycleDetector
+}
+
+func (this *differ) add(op string, path []string, oldValue, newValue any) {
+	this.ops = append(this.ops, DiffOperation{
+		Op:       op,
+		Path:     SliceToJSONPointer(path),
+		OldValue: oldValue,
+		NewValue: newValue,
+	})
+}
+
+func (this *differ) diff(path []string, a, b any) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !this.seenA.enter(va) {
+		this.diffValue(path, a, b)
+		return
+	}
+	defer this.seenA.leave(va)
+
+	if !this.seenB.enter(vb) {
+		this.diffValue(path, a, b)
+		return
+	}
+	defer this.seenB.leave(vb)
+
+	switch av := this.arr.anyDataFormat(a).(type) {
+	case map[string]any:
+		if bv, ok := this.arr.anyDataFormat(b).(map[string]any); ok {
+			this.diffMap(path, av, bv)
+			return
+		}
+	case []any:
+		if bv, ok := this.arr.anyDataFormat(b).([]any); ok {
+			if this.opts.lcs {
+				this.diffSliceLCS(path, av, bv)
+			} else {
+				this.diffSlice(path, av, bv)
+			}
+
+			return
+		}
+	}
+
+	this.diffValue(path, a, b)
+}
+
+// 按值比较
+// diff a and b as whole values
+func (this *differ) diffValue(path []string, a, b any) {
+	if !this.arr.queryEqual(a, b) {
+		this.add("replace", path, a, b)
+	}
+}
+
+func (this *differ) diffMap(path []string, a, b map[string]any) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		av, aok := a[k]
+		bv, bok := b[k]
+
+		switch {
+		case aok && bok:
+			this.diff(appendPath(path, k), av, bv)
+		case aok:
+			this.add("remove", appendPath(path, k), av, nil)
+		default:
+			this.add("add", appendPath(path, k), nil, bv)
+		}
+	}
+}
+
+// 按索引比较
+// diff slices by index
+func (this *differ) diffSlice(path []string, a, b []any) {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+
+	for i := 0; i < n; i++ {
+		this.diff(appendPath(path, strconv.Itoa(i)), a[i], b[i])
+	}
+
+	for i := n; i < len(b); i++ {
+		this.add("add", appendPath(path, strconv.Itoa(i)), nil, b[i])
+	}
+
+	for i := len(a) - 1; i >= n; i-- {
+		this.add("remove", appendPath(path, strconv.Itoa(i)), a[i], nil)
+	}
+}
+
+// 按 LCS 比较
+// diff slices by longest common subsequence
+func (this *differ) diffSliceLCS(path []string, a, b []any) {
+	// lcs[i][j] 为 a[i:] 和 b[j:] 的 LCS 长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if this.arr.queryEqual(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// k 为修改中数组的位置
+	// k is the index in the array being patched
+	i, j, k := 0, 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && this.arr.queryEqual(a[i], b[j]):
+			i, j, k = i+1, j+1, k+1
+		case i < len(a) && j < len(b) && lcs[i+1][j+1] == lcs[i][j]:
+			// 删除后插入合并为修改
+			// a remove followed by an add is a change
+			this.diff(appendPath(path, strconv.Itoa(k)), a[i], b[j])
+			i, j, k = i+1, j+1, k+1
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			this.add("add", appendPath(path, strconv.Itoa(k)), nil, b[j])
+			j, k = j+1, k+1
+		default:
+			this.add("remove", appendPath(path, strconv.Itoa(k)), a[i], nil)
+			i++
+		}
+	}
+}
+
+func appendPath(path []string, key string) []string {
+	res := make([]string, len(path)+1)
+	copy(res, path)
+	res[len(path)] = key
+
+	return res
+}
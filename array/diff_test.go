@@ -0,0 +1,119 @@
+package array
+
+import (
+	"testing"
+)
+
+func Test_Diff(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	a := New(map[string]any{
+		"name": "app",
+		"port": 80,
+		"tags": []any{"a", "b"},
+		"db": map[any]any{
+			"host": "localhost",
+			"user": "root",
+		},
+	})
+	b := New(map[string]any{
+		"name": "app",
+		"port": 8080,
+		"tags": []any{"a", "b", "c"},
+		"db": map[string]any{
+			"host": "db.host",
+		},
+		"debug": true,
+	})
+
+	check := DiffOperations{
+		{Op: "replace", Path: "/db/host", OldValue: "localhost", NewValue: "db.host"},
+		{Op: "remove", Path: "/db/user", OldValue: "root"},
+		{Op: "add", Path: "/debug", NewValue: true},
+		{Op: "replace", Path: "/port", OldValue: 80, NewValue: 8080},
+		{Op: "add", Path: "/tags/2", NewValue: "c"},
+	}
+
+	assert(Diff(a, b), check, "Diff")
+
+	patch, err := Diff(a, b).ToJSONPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(string(patch), `[{"op":"replace","path":"/db/host","value":"db.host"},{"op":"remove","path":"/db/user"},{"op":"add","path":"/debug","value":true},{"op":"replace","path":"/port","value":8080},{"op":"add","path":"/tags/2","value":"c"}]`, "ToJSONPatch")
+
+	assert(len(Diff(a, a)), 0, "Diff same")
+	assert(len(Diff(New(map[string]any{"a": 1}), New(map[string]any{"a": float64(1)}))), 0, "Diff numbers")
+}
+
+func Test_DiffCycle(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	n := newTestStructCycle()
+	assert(len(Diff(New(n), New(n))), 0, "Diff same cycle")
+
+	m := newTestStructCycle()
+	m.Name = "m"
+
+	check := DiffOperations{
+		{Op: "replace", Path: "/name", OldValue: "n", NewValue: "m"},
+		{Op: "replace", Path: "/parent", OldValue: n, NewValue: m},
+	}
+	assert(Diff(New(n), New(m)), check, "Diff cycles")
+}
+
+func Test_DiffApplyPatch(t *testing.T) {
+	assert := assertT(t)
+
+	testData := []struct {
+		name string
+		a    string
+		b    string
+	}{
+		{"map", `{"a":1,"b":{"c":[1,2,3]}}`, `{"a":2,"b":{"c":[1,3],"d":null}}`},
+		{"slice grow", `[1,2]`, `[0,1,2,3]`},
+		{"slice shrink", `[0,1,2,3]`, `[1,3]`},
+		{"slice change", `[{"a":1},{"b":2},3]`, `[{"a":2},3,{"c":4}]`},
+		{"root", `{"a":1}`, `[1]`},
+	}
+
+	for _, v := range testData {
+		for _, lcs := range []bool{false, true} {
+			a, _ := ParseJSON([]byte(v.a))
+			b, _ := ParseJSON([]byte(v.b))
+
+			opts := []DiffOption{}
+			if lcs {
+				opts = append(opts, WithDiffLCS())
+			}
+
+			patch, err := Diff(a, b, opts...).ToJSONPatch()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := a.ApplyPatch(patch); err != nil {
+				t.Fatalf("%s: %v, patch: %s", v.name, err, patch)
+			}
+
+			assert(a.String(), b.String(), v.name)
+		}
+	}
+}
+
+func Test_DiffLCS(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	a, _ := ParseJSON([]byte(`["a","b","c","d"]`))
+	b, _ := ParseJSON([]byte(`["x","a","c","d","e"]`))
+
+	check := DiffOperations{
+		{Op: "add", Path: "/0", NewValue: "x"},
+		{Op: "remove", Path: "/2", OldValue: "b"},
+		{Op: "add", Path: "/4", NewValue: "e"},
+	}
+
+	assert(Diff(a, b, WithDiffLCS()), check, "Diff LCS")
+	assert(len(Diff(a, b)), 3, "Diff by index")
+}
@@ -0,0 +1,69 @@
+package array
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrTypeMismatch = errors.New("type mismatch")
+	ErrInvalidIndex = errors.New("invalid index")
+	ErrInvalidPath  = errors.New("invalid path")
+	ErrCollision    = errors.New("value collision")
+	ErrOutOfBounds  = errors.New("out of bounds")
+	ErrCycle        = errors.New("encountered a cycle")
+)
+
+// 路径错误
+// PathError records the operation and the path that failed. Segment is
+// the index of the failed path segment, or -1 when the whole path failed.
+// Err wraps one of ErrNotFound, ErrTypeMismatch, ErrInvalidIndex,
+// ErrInvalidPath, ErrCollision or ErrOutOfBounds, so use errors.Is to
+// check the cause.
+type PathError struct {
+	Op      string
+	Path    string
+	Segment int
+	Err     error
+}
+
+func (e *PathError) Error() string {
+	if e.Segment < 0 {
+		return fmt.Sprintf("%s '%s': %v", e.Op, e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("%s '%s': segment %d: %v", e.Op, e.Path, e.Segment, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// 创建路径错误
+// returns a PathError of path, err is the cause and can be formatted
+// with args
+func (this *Array) pathError(op string, path []any, segment int, err error, format string, args ...any) *PathError {
+	if format != "" {
+		err = fmt.Errorf("%w: "+format, append([]any{err}, args...)...)
+	}
+
+	return &PathError{
+		Op:      op,
+		Path:    KeyDelimSliceToPath(formatPathString(path), this.keyDelim),
+		Segment: segment,
+		Err:     err,
+	}
+}
+
+// 获取第一个不存在的路径索引
+// returns the index of the first path segment that is not found
+func (this *Array) missingSegment(path []string) int {
+	for i := 1; i <= len(path); i++ {
+		if !this.Search(path[:i]...).Found() {
+			return i - 1
+		}
+	}
+
+	return len(path) - 1
+}
@@ -0,0 +1,148 @@
+package array
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_PathError(t *testing.T) {
+	assert := assertT(t)
+
+	newData := func() *Array {
+		arr, _ := ParseJSON([]byte(`{"a":{"b":[1,2],"c":"s"},"d":[{"e":1}]}`))
+		return arr
+	}
+
+	testData := []struct {
+		fn      func(arr *Array) error
+		cause   error
+		op      string
+		path    string
+		segment int
+	}{
+		{
+			func(arr *Array) error { _, err := arr.Set(1, "a", "b", "x"); return err },
+			ErrInvalidIndex, "set", "a.b.x", 2,
+		},
+		{
+			func(arr *Array) error { _, err := arr.Set(1, "a", "b", "5"); return err },
+			ErrOutOfBounds, "set", "a.b.5", 2,
+		},
+		{
+			func(arr *Array) error { _, err := arr.Set(1, "a", "c", "x"); return err },
+			ErrCollision, "set", "a.c.x", 2,
+		},
+		{
+			func(arr *Array) error { _, err := arr.Set(1, "d", "0", "e", "f"); return err },
+			ErrCollision, "set", "d.0.e.f", 3,
+		},
+		{
+			func(arr *Array) error { _, err := arr.Sub("a.b").SetIndex(1, 9); return err },
+			ErrOutOfBounds, "set", "9", 0,
+		},
+		{
+			func(arr *Array) error { _, err := arr.Sub("a.c").SetIndex(1, 0); return err },
+			ErrTypeMismatch, "set", "0", 0,
+		},
+		{
+			func(arr *Array) error { return arr.Delete("a", "x") },
+			ErrNotFound, "delete", "a.x", 1,
+		},
+		{
+			func(arr *Array) error { return arr.Delete("x", "y", "z") },
+			ErrNotFound, "delete", "x.y.z", 0,
+		},
+		{
+			func(arr *Array) error { return arr.Delete("a", "b", "y") },
+			ErrInvalidIndex, "delete", "a.b.y", 2,
+		},
+		{
+			func(arr *Array) error { return arr.Delete("a", "b", "7") },
+			ErrOutOfBounds, "delete", "a.b.7", 2,
+		},
+		{
+			func(arr *Array) error { return arr.Delete("a", "c", "x") },
+			ErrCollision, "delete", "a.c.x", 2,
+		},
+		{
+			func(arr *Array) error { return arr.Delete() },
+			ErrInvalidPath, "delete", "", -1,
+		},
+		{
+			func(arr *Array) error { var nilArr *Array; return nilArr.Delete("x", "y") },
+			ErrNotFound, "delete", "x.y", -1,
+		},
+		{
+			func(arr *Array) error { _, err := arr.JSONPointer("a/b"); return err },
+			ErrInvalidPath, "parse", "a/b", -1,
+		},
+		{
+			func(arr *Array) error { _, err := GetAs[int](arr, "a.c"); return err },
+			ErrTypeMismatch, "get", "a.c", -1,
+		},
+	}
+
+	for _, v := range testData {
+		err := v.fn(newData())
+
+		var pathErr *PathError
+		if !errors.As(err, &pathErr) {
+			t.Errorf("PathError %s '%s' got %v", v.op, v.path, err)
+			continue
+		}
+
+		assert(errors.Is(err, v.cause), "true", "PathError cause "+v.path)
+		assert(pathErr.Op, v.op, "PathError op "+v.path)
+		assert(pathErr.Path, v.path, "PathError path "+v.path)
+		assert(pathErr.Segment, toString(v.segment), "PathError segment "+v.path)
+	}
+
+	arr := New(map[string]any{"a": 1})
+	err := arr.Merge([]*Array{New(map[string]any{"a": 2})}, WithConflictStrategy(ConflictError))
+	assert(errors.Is(err, ErrMergeConflict), "true", "Merge PathError cause")
+	assert(err.Error(), "merge 'a': merge conflict", "Merge PathError message")
+
+	_, err = Unflatten(map[string]any{"a": 1, "a.b": 2})
+	assert(errors.Is(err, ErrCollision), "true", "Unflatten PathError cause")
+
+	err = New(map[string]any{"a": 1}).ApplyPatch([]byte(`[{"op":"remove","path":"/b"}]`))
+	assert(errors.Is(err, ErrNotFound), "true", "ApplyPatch cause")
+
+	patchData := []struct {
+		patch   string
+		cause   error
+		path    string
+		segment int
+	}{
+		{`[{"op":"remove","path":"/b/c"}]`, ErrNotFound, "b.c", 0},
+		{`[{"op":"replace","path":"/a/x","value":1}]`, ErrNotFound, "a.x", 1},
+		{`[{"op":"test","path":"/a/b","value":3}]`, ErrPatchTestFailed, "a.b", -1},
+		{`[{"op":"copy","from":"/x","path":"/y"}]`, ErrNotFound, "x", 0},
+		{`[{"op":"move","from":"/a","path":"/a/z"}]`, ErrInvalidPath, "a.z", -1},
+		{`[{"op":"add","path":"/x/y/z","value":1}]`, ErrNotFound, "x.y.z", 0},
+		{`[{"op":"add","path":"/a/b/9","value":1}]`, ErrOutOfBounds, "a.b.9", 2},
+		{`[{"op":"add","path":"/a/b/y","value":1}]`, ErrInvalidIndex, "a.b.y", 2},
+	}
+
+	for _, v := range patchData {
+		arr, _ := ParseJSON([]byte(`{"a":{"b":[1,2]}}`))
+		err := arr.ApplyPatch([]byte(v.patch))
+
+		var patchErr *PatchError
+		assert(errors.As(err, &patchErr), "true", "ApplyPatch PatchError "+v.patch)
+
+		var pathErr *PathError
+		if !errors.As(err, &pathErr) {
+			t.Errorf("ApplyPatch PathError %s got %v", v.patch, err)
+			continue
+		}
+
+		assert(errors.Is(err, v.cause), "true", "ApplyPatch PathError cause "+v.patch)
+		assert(pathErr.Op, "patch", "ApplyPatch PathError op "+v.patch)
+		assert(pathErr.Path, v.path, "ApplyPatch PathError path "+v.patch)
+		assert(pathErr.Segment, toString(v.segment), "ApplyPatch PathError segment "+v.patch)
+	}
+
+	_, err = New(map[string]any{"a": "s"}).SetPointer(1, "/a/b")
+	assert(errors.Is(err, ErrCollision), "true", "SetPointer cause")
+}
@@ -0,0 +1,316 @@
+package array
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// 扁平化键名格式
+// FlattenStyle is the key style of FlattenWith
+type FlattenStyle int
+
+const (
+	// 分隔符格式 / keys like `a.b.0.c`
+	FlattenKeyDelim FlattenStyle = iota
+	// 中括号格式 / keys like `a.b[0].c`
+	FlattenBracket
+	// JSON Pointer 格式 / keys like `/a/b/0/c`
+	FlattenJSONPointer
+)
+
+// 扁平化设置
+// flatten options
+type flattenOptions struct {
+	keyDelim     string
+	style        FlattenStyle
+	escape       bool
+	includeEmpty bool
+	maxDepth     int
+
+	// 循环引用检测 / cycle detection of the walk
+	seen cycleDetector
+}
+
+// 扁平化设置
+// FlattenOption configures FlattenWith
+type FlattenOption func(*flattenOptions)
+
+// 设置分隔符，默认为 Array 的分隔符
+// WithFlattenKeyDelim sets the key delimiter, the default is the Array's keyDelim
+func WithFlattenKeyDelim(keyDelim string) FlattenOption {
+	return func(opts *flattenOptions) {
+		opts.keyDelim = keyDelim
+	}
+}
+
+// 设置键名格式
+// WithFlattenStyle sets the key style
+func WithFlattenStyle(style FlattenStyle) FlattenOption {
+	return func(opts *flattenOptions) {
+		opts.style = style
+	}
+}
+
+// 转义键名中的 `~` 和分隔符，和 KeyDelimPathToSlice 一致
+// WithFlattenEscape escapes `~` as `~0` and the delimiter as `~1` in keys,
+// the keys can be parsed with KeyDelimPathToSlice and used with Get
+func WithFlattenEscape() FlattenOption {
+	return func(opts *flattenOptions) {
+		opts.escape = true
+	}
+}
+
+// 包括空数组和空对象
+// WithFlattenIncludeEmpty includes empty arrays and objects, as
+// FlattenIncludeEmpty does
+func WithFlattenIncludeEmpty(includeEmpty bool) FlattenOption {
+	return func(opts *flattenOptions) {
+		opts.includeEmpty = includeEmpty
+	}
+}
+
+// 设置最大层级，超过层级的数据不再扁平化
+// WithFlattenMaxDepth stops flattening below the depth, 0 is no limit
+func WithFlattenMaxDepth(depth int) FlattenOption {
+	return func(opts *flattenOptions) {
+		opts.maxDepth = depth
+	}
+}
+
+// 拼接键名
+// join the key of the child to path
+func (this *flattenOptions) joinKey(path string, depth int, key string, index bool) string {
+	switch {
+	case this.style == FlattenJSONPointer:
+		return path + "/" + r4.Replace(key)
+	case this.style == FlattenBracket && index:
+		return path + "[" + key + "]"
+	}
+
+	if this.escape {
+		key = KeyDelimSliceToPath([]string{key}, this.keyDelim)
+	}
+
+	if depth == 0 {
+		return key
+	}
+
+	return path + this.keyDelim + key
+}
+
+// 扁平化数据
+// FlattenWith flattens a map or slice into key/value pairs with the
+// options, keys are joined with the Array's keyDelim by default
+func (this *Array) FlattenWith(opts ...FlattenOption) (map[string]any, error) {
+	options := &flattenOptions{
+		keyDelim: this.keyDelim,
+		seen:     cycleDetector{},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	flattened := map[string]any{}
+
+	sourceValue := reflect.ValueOf(this.source)
+	options.seen.enter(sourceValue)
+
+	var err error
+	switch t := this.anyOutputFormat(this.source).(type) {
+	case map[string]any:
+		err = this.walkObject("", 0, t, flattened, options)
+	case []any:
+		err = this.walkArray("", 0, t, flattened, options)
+	default:
+		return nil, fmt.Errorf("%w: %T is not a map or slice", ErrTypeMismatch, this.source)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return flattened, nil
+}
+
+// 还原设置
+// unflatten options
+type unflattenOptions struct {
+	keyDelim string
+}
+
+// 还原设置
+// UnflattenOption configures Unflatten
+type UnflattenOption func(*unflattenOptions)
+
+// 设置分隔符
+// WithUnflattenKeyDelim sets the delimiter of the flat keys
+func WithUnflattenKeyDelim(keyDelim string) UnflattenOption {
+	return func(opts *unflattenOptions) {
+		opts.keyDelim = keyDelim
+	}
+}
+
+// 还原扁平数据
+// Unflatten rebuilds the nested data from the keys of Flatten or
+// FlattenIncludeEmpty, use FlattenWith with WithFlattenEscape for keys
+// which have the delimiter. Keys are split at the delimiter with `~1` as
+// the delimiter and `~0` as `~`, numeric segments build slices with gaps
+// filled by nil, and the struct{}{} and []struct{}{} markers become empty
+// objects and arrays. Indexes leaving too large gaps build maps instead
+// of slices.
+func Unflatten(flat map[string]any, opts ...UnflattenOption) (*Array, error) {
+	options := &unflattenOptions{
+		keyDelim: ".",
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	root := &unflattenNode{}
+	for _, k := range keys {
+		var path []string
+		if k != "" || !isEmptyMarker(flat[k]) {
+			path = KeyDelimPathToSlice(k, options.keyDelim)
+		}
+
+		if err := root.set(path, flat[k]); err != nil {
+			return nil, &PathError{
+				Op:      "unflatten",
+				Path:    k,
+				Segment: -1,
+				Err:     err,
+			}
+		}
+	}
+
+	return New(root.value()).WithKeyDelim(options.keyDelim), nil
+}
+
+// 还原扁平数据
+// Unflatten rebuilds the nested data with the Array's keyDelim
+func (this *Array) Unflatten(flat map[string]any) (*Array, error) {
+	res, err := Unflatten(flat, WithUnflattenKeyDelim(this.keyDelim))
+	if err != nil {
+		return nil, err
+	}
+
+	return this.derive(res.source), nil
+}
+
+type unflattenNode struct {
+	leaf     bool
+	data     any
+	empty    any
+	children map[string]*unflattenNode
+}
+
+func (this *unflattenNode) set(path []string, value any) error {
+	if len(path) == 0 {
+		if isEmptyMarker(value) {
+			if this.leaf {
+				return fmt.Errorf("%w: value collides with '%v'", ErrCollision, this.data)
+			}
+
+			this.empty = value
+			return nil
+		}
+
+		if this.leaf || len(this.children) > 0 {
+			return fmt.Errorf("%w: value collides with other keys", ErrCollision)
+		}
+
+		this.leaf = true
+		this.data = value
+		return nil
+	}
+
+	if this.leaf {
+		return fmt.Errorf("%w: key collides with value '%v'", ErrCollision, this.data)
+	}
+
+	if this.children == nil {
+		this.children = make(map[string]*unflattenNode)
+	}
+
+	child, ok := this.children[path[0]]
+	if !ok {
+		child = &unflattenNode{}
+		this.children[path[0]] = child
+	}
+
+	return child.set(path[1:], value)
+}
+
+func (this *unflattenNode) value() any {
+	if this.leaf {
+		return this.data
+	}
+
+	if len(this.children) == 0 {
+		if _, ok := this.empty.([]struct{}); ok {
+			return []any{}
+		}
+
+		return map[string]any{}
+	}
+
+	if size, ok := this.sliceSize(); ok {
+		res := make([]any, size)
+		for k, child := range this.children {
+			index, _ := strconv.Atoi(k)
+			res[index] = child.value()
+		}
+
+		return res
+	}
+
+	res := make(map[string]any, len(this.children))
+	for k, child := range this.children {
+		res[k] = child.value()
+	}
+
+	return res
+}
+
+// 子级都为索引时返回切片长度，空数据过多时作为 map 使用
+// returns the slice size when all of the children are indexes, children
+// with too many gaps between them are used as a map
+func (this *unflattenNode) sliceSize() (int, bool) {
+	size := 0
+	for k := range this.children {
+		index, err := strconv.Atoi(k)
+		if err != nil || index < 0 || strconv.Itoa(index) != k {
+			return 0, false
+		}
+
+		if index >= size {
+			size = index + 1
+		}
+	}
+
+	if size-len(this.children) > maxSliceGrowth {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// 判断是否为空数据标记
+// if the value is an empty object or array marker of FlattenIncludeEmpty
+func isEmptyMarker(value any) bool {
+	switch value.(type) {
+	case struct{}, []struct{}:
+		return true
+	}
+
+	return false
+}
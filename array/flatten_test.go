@@ -0,0 +1,204 @@
+package array
+
+import (
+	"testing"
+)
+
+func Test_Unflatten(t *testing.T) {
+	assert := assertT(t)
+
+	testData := []struct {
+		flat     map[string]any
+		expected string
+	}{
+		{
+			map[string]any{"foo.0.bar": "1", "foo.1.bar": "2"},
+			`{"foo":[{"bar":"1"},{"bar":"2"}]}`,
+		},
+		{
+			map[string]any{"0.a": 1, "2": 3},
+			`[{"a":1},null,3]`,
+		},
+		{
+			map[string]any{"a.01": 1, "a.1": 2},
+			`{"a":{"01":1,"1":2}}`,
+		},
+		{
+			map[string]any{"a~1b.c~0d": 1},
+			`{"a.b":{"c~d":1}}`,
+		},
+		{
+			map[string]any{"a": struct{}{}, "b": []struct{}{}, "c.0": []struct{}{}},
+			`{"a":{},"b":[],"c":[[]]}`,
+		},
+		{
+			map[string]any{"": []struct{}{}},
+			`[]`,
+		},
+		{
+			map[string]any{},
+			`{}`,
+		},
+	}
+
+	for _, v := range testData {
+		arr, err := Unflatten(v.flat)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert(arr.String(), v.expected, "Unflatten")
+	}
+
+	arr, err := Unflatten(map[string]any{"a/b": 1, "a/c": 2}, WithUnflattenKeyDelim("/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr.String(), `{"a":{"b":1,"c":2}}`, "Unflatten keyDelim")
+	assert(arr.Get("a/c"), "2", "Unflatten keyDelim Get")
+
+	if _, err := Unflatten(map[string]any{"a": 1, "a.b": 2}); err == nil {
+		t.Error("Unflatten should error on key collision")
+	}
+
+	arr, err = Unflatten(map[string]any{"ids.99999999999999": 1, "ids.1": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr.String(), `{"ids":{"1":2,"99999999999999":1}}`, "Unflatten large index")
+}
+
+func Test_Unflatten_RoundTrip(t *testing.T) {
+	assert := assertT(t)
+
+	data := `{"foo":[{"bar":"1"},{"bar":"2"},{"bar222":{}}],"list":[],"n":null}`
+
+	json1, _ := ParseJSON([]byte(data))
+
+	flat, err := json1.FlattenIncludeEmpty()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arr, err := json1.Unflatten(flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr.String(), data, "Unflatten round trip")
+}
+
+func Test_FlattenWith(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	json1, _ := ParseJSON([]byte(`{"foo":[{"bar":"1"},{"a.b":"2"}],"x~y":{"z":{}}}`))
+
+	testData := []struct {
+		opts     []FlattenOption
+		expected map[string]any
+		msg      string
+	}{
+		{
+			nil,
+			map[string]any{"foo.0.bar": "1", "foo.1.a.b": "2"},
+			"default",
+		},
+		{
+			[]FlattenOption{WithFlattenEscape(), WithFlattenIncludeEmpty(true)},
+			map[string]any{"foo.0.bar": "1", "foo.1.a~1b": "2", "x~0y.z": struct{}{}},
+			"escape",
+		},
+		{
+			[]FlattenOption{WithFlattenKeyDelim("/"), WithFlattenEscape()},
+			map[string]any{"foo/0/bar": "1", "foo/1/a.b": "2"},
+			"keyDelim",
+		},
+		{
+			[]FlattenOption{WithFlattenStyle(FlattenBracket)},
+			map[string]any{"foo[0].bar": "1", "foo[1].a.b": "2"},
+			"bracket",
+		},
+		{
+			[]FlattenOption{WithFlattenStyle(FlattenJSONPointer), WithFlattenIncludeEmpty(true)},
+			map[string]any{"/foo/0/bar": "1", "/foo/1/a.b": "2", "/x~0y/z": struct{}{}},
+			"json pointer",
+		},
+		{
+			[]FlattenOption{WithFlattenMaxDepth(2)},
+			map[string]any{
+				"foo.0": map[string]any{"bar": "1"},
+				"foo.1": map[string]any{"a.b": "2"},
+				"x~y.z": map[string]any{},
+			},
+			"max depth",
+		},
+	}
+
+	for _, v := range testData {
+		flat, err := json1.FlattenWith(v.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert(flat, v.expected, "FlattenWith "+v.msg)
+	}
+
+	json2, _ := ParseJSON([]byte(`[[1],{"a":2}]`))
+
+	flat, _ := json2.FlattenWith(WithFlattenStyle(FlattenBracket))
+	assert(flat, map[string]any{"[0][0]": float64(1), "[1].a": float64(2)}, "FlattenWith bracket root")
+
+	flat, _ = json2.WithKeyDelim("/").Flatten()
+	assert(flat, map[string]any{"0/0": float64(1), "1/a": float64(2)}, "Flatten keyDelim")
+
+	if _, err := New(1).FlattenWith(); err == nil {
+		t.Error("FlattenWith should error on scalar")
+	}
+}
+
+func Test_FlattenWith_RoundTrip(t *testing.T) {
+	assert := assertT(t)
+
+	data := `{"a/b":{"c.d":[1,{"e~f":{}}]}}`
+
+	json1, _ := ParseJSON([]byte(data))
+
+	for _, delim := range []string{".", "/", "::"} {
+		arr := json1.WithKeyDelim(delim)
+
+		flat, err := arr.FlattenWith(WithFlattenEscape(), WithFlattenIncludeEmpty(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := arr.Unflatten(flat)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert(res.String(), data, "FlattenWith round trip "+delim)
+	}
+}
+
+func Test_FlattenWith_EscapeGet(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	json1, _ := ParseJSON([]byte(`{"a.b":{"c/d":[[1]],"e~f":"2"},"g~1h":"3"}`))
+
+	for _, delim := range []string{".", "/", "::"} {
+		arr := json1.WithKeyDelim(delim)
+
+		flat, err := arr.FlattenWith(WithFlattenEscape())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert(len(flat), 3, "FlattenWith escape len "+delim)
+
+		for k, v := range flat {
+			assert(arr.Get(k), v, "FlattenWith escape Get "+delim+" "+k)
+		}
+	}
+}
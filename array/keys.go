@@ -0,0 +1,278 @@
+package array
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 键名格式化
+// KeyNormalizer converts a key to its normalized form, keys with the same
+// normalized form match each other
+type KeyNormalizer func(key string) string
+
+// 设置键名不区分大小写
+// WithCaseInsensitive matches map keys and struct fields case-insensitively
+func WithCaseInsensitive(caseInsensitive bool) Option {
+	return func(conf *config) {
+		conf.caseInsensitive = caseInsensitive
+	}
+}
+
+// 设置键名格式化，如 SnakeCase, CamelCase 和 KebabCase
+// WithKeyNormalizer matches the keys with the same normalized form, like
+// SnakeCase, CamelCase and KebabCase
+func WithKeyNormalizer(normalizer KeyNormalizer) Option {
+	return func(conf *config) {
+		conf.keyNormalizer = normalizer
+	}
+}
+
+// 转为蛇形命名，如 db_host
+// SnakeCase converts the key to snake_case, like db_host
+func SnakeCase(key string) string {
+	return strings.Join(splitKeyWords(key), "_")
+}
+
+// 转为短横线命名，如 db-host
+// KebabCase converts the key to kebab-case, like db-host
+func KebabCase(key string) string {
+	return strings.Join(splitKeyWords(key), "-")
+}
+
+// 转为驼峰命名，如 dbHost
+// CamelCase converts the key to camelCase, like dbHost
+func CamelCase(key string) string {
+	words := splitKeyWords(key)
+	for i := 1; i < len(words); i++ {
+		r := []rune(words[i])
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+
+	return strings.Join(words, "")
+}
+
+// 拆分键名为小写单词，以分隔符和大小写变化拆分，如 DBHost 拆分为 db 和 host
+// split the key to lower case words at separators and case changes,
+// DBHost is split to db and host
+func splitKeyWords(key string) []string {
+	runes := []rune(key)
+
+	var words []string
+	start := -1
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' || r == '.' {
+			if start >= 0 {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+
+	return words
+}
+
+// 判断是否匹配键名
+// if the key k matches key with the case-insensitive and normalizer modes
+func (this *Array) matchKey(k, key string) bool {
+	if k == key {
+		return true
+	}
+
+	if this.caseInsensitive && strings.EqualFold(k, key) {
+		return true
+	}
+
+	if this.keyNormalizer != nil {
+		nk, nkey := this.keyNormalizer(k), this.keyNormalizer(key)
+		if nk == nkey || (this.caseInsensitive && strings.EqualFold(nk, nkey)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 是否开启键名匹配
+// if keys are matched by the case-insensitive or normalizer modes
+func (this *Array) matchesKeys() bool {
+	return this.caseInsensitive || this.keyNormalizer != nil
+}
+
+// 查找键名的原始写法，多个匹配时使用排序后的第一个
+// returns the original spelling of key in m, the first sorted key is
+// used when more than one key matches
+func (this *Array) lookupKey(m map[string]any, key string) (string, bool) {
+	if _, ok := m[key]; ok || !this.matchesKeys() {
+		return key, ok
+	}
+
+	var keys []string
+	for k := range m {
+		if this.matchKey(k, key) {
+			keys = append(keys, k)
+		}
+	}
+
+	if len(keys) == 0 {
+		return key, false
+	}
+
+	sort.Strings(keys)
+
+	return keys[0], true
+}
+
+// 查找反射 map 的键名
+// returns the key of the reflect map m that matches key
+func (this *Array) lookupValueKey(m reflect.Value, key string) (reflect.Value, bool) {
+	var found reflect.Value
+	var foundKey string
+
+	iter := m.MapRange()
+	for iter.Next() {
+		k := mapKeyString(iter.Key().Interface())
+		if k == key {
+			return iter.Key(), true
+		}
+
+		if this.matchesKeys() && this.matchKey(k, key) && (!found.IsValid() || k < foundKey) {
+			found, foundKey = iter.Key(), k
+		}
+	}
+
+	return found, found.IsValid()
+}
+
+// 获取反射 map 的键名，已有的键名优先，接口类型的键名按照字符串形式查找
+// returns the key of the reflect map m for the path segment key, existing
+// keys are preferred and interface keys are matched by their string form
+// like reads, found reports if the key exists
+func (this *Array) resolveMapKey(m reflect.Value, key any) (res reflect.Value, found bool, err error) {
+	keyType := m.Type().Key()
+
+	res, err = convertKey(key, keyType)
+	if err == nil && m.MapIndex(res).IsValid() {
+		return res, true, nil
+	}
+
+	if keyType.Kind() == reflect.Interface || this.matchesKeys() {
+		if k, ok := this.lookupValueKey(m, toString(key)); ok {
+			return k, true, nil
+		}
+	}
+
+	return res, false, err
+}
+
+// 查找结构体字段
+// returns the struct field of typ that matches name
+func (this *Array) lookupField(typ reflect.Type, name string) (structField, bool) {
+	if field, ok := findStructField(typ, this.tagName, name); ok || !this.matchesKeys() {
+		return field, ok
+	}
+
+	for _, f := range getStructFields(typ, this.tagName) {
+		if this.matchKey(f.name, name) {
+			return f, true
+		}
+	}
+
+	return structField{}, false
+}
+
+// 转换键名类型，数字和布尔字符串会被解析，实现 encoding.TextUnmarshaler
+// 的类型使用键名文本解析
+// convert the key to typ, numeric and bool strings are parsed and types
+// implementing encoding.TextUnmarshaler unmarshal the key text
+func convertKey(key any, typ reflect.Type) (reflect.Value, error) {
+	if key != nil && reflect.TypeOf(key).AssignableTo(typ) {
+		return reflect.ValueOf(key), nil
+	}
+
+	s, ok := toStringE(key)
+	if !ok || key == nil {
+		return reflect.Value{}, fmt.Errorf("%w: key %T is not %s", ErrTypeMismatch, key, typ)
+	}
+
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		res := reflect.New(typ)
+		if err := res.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: key '%s' is not %s: %s", ErrTypeMismatch, s, typ, err.Error())
+		}
+
+		return res.Elem(), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(s).Convert(typ), nil
+	case reflect.Interface:
+		if reflect.TypeOf(s).AssignableTo(typ) {
+			return reflect.ValueOf(s).Convert(typ), nil
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return reflect.ValueOf(b).Convert(typ), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if num, ok := parseNumber(s); ok {
+			res, err := convertNumber(num, typ, true)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%w: key %s", ErrTypeMismatch, err.Error())
+			}
+
+			return res, nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("%w: key '%s' is not %s", ErrTypeMismatch, s, typ)
+}
+
+// map 键名转为字符串，和 FromStruct 一致
+// convert the map key to string like FromStruct
+func mapKeyString(k any) string {
+	if k == nil {
+		return ""
+	}
+
+	key, err := normalizeMapKey(reflect.ValueOf(k), &normalizeOptions{
+		tagName: defaultTagName,
+		seen:    cycleDetector{},
+	})
+	if err != nil {
+		// 循环引用的键名使用默认格式
+		// cyclic keys use the default format
+		return fmt.Sprint(k)
+	}
+
+	return key
+}
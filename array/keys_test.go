@@ -0,0 +1,230 @@
+package array
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func Test_KeyCase(t *testing.T) {
+	assert := assertT(t)
+
+	testData := []struct {
+		key   string
+		snake string
+		kebab string
+		camel string
+	}{
+		{"DBHost", "db_host", "db-host", "dbHost"},
+		{"db_host", "db_host", "db-host", "dbHost"},
+		{"db-host", "db_host", "db-host", "dbHost"},
+		{"dbHost", "db_host", "db-host", "dbHost"},
+		{"HTTPServer2Port", "http_server2_port", "http-server2-port", "httpServer2Port"},
+		{"user id", "user_id", "user-id", "userId"},
+		{"__a__b", "a_b", "a-b", "aB"},
+		{"", "", "", ""},
+	}
+
+	for _, v := range testData {
+		assert(SnakeCase(v.key), v.snake, "SnakeCase "+v.key)
+		assert(KebabCase(v.key), v.kebab, "KebabCase "+v.key)
+		assert(CamelCase(v.key), v.camel, "CamelCase "+v.key)
+	}
+}
+
+func Test_WithCaseInsensitive(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	data := map[string]any{
+		"DBHost": "localhost",
+		"Server": map[string]any{"Port": 80},
+		"Typed":  map[string]int{"Size": 1},
+	}
+
+	arr := New(data, WithCaseInsensitive(true))
+
+	assert(arr.Get("dbhost"), "localhost", "Get dbhost")
+	assert(arr.Get("server.port"), 80, "Get server.port")
+	assert(arr.Get("typed.size"), 1, "Get typed.size")
+	assert(arr.Exists("SERVER.PORT"), true, "Exists SERVER.PORT")
+	assert(arr.Exists("server.host"), false, "Exists server.host")
+	assert(New(data).Exists("dbhost"), false, "Exists case-sensitive")
+
+	if _, err := arr.Set(8080, "server", "port"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["Server"], map[string]any{"Port": 8080}, "Set original spelling")
+
+	if _, err := arr.Set(2, "typed", "SIZE"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["Typed"], map[string]int{"Size": 2}, "Set typed original spelling")
+
+	if err := arr.Delete("typed", "size"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("Typed"), map[string]int{}, "Delete typed")
+
+	if err := arr.Delete("dbhost"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Exists("DBHost"), false, "Delete dbhost")
+
+	type Config struct {
+		DBHost string
+	}
+
+	conf := &Config{}
+	if _, err := New(conf, WithCaseInsensitive(true)).Set("db", "dbhost"); err != nil {
+		t.Fatal(err)
+	}
+	assert(conf.DBHost, "db", "Set struct field")
+}
+
+func Test_WithKeyNormalizer(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	data := map[string]any{
+		"db_host": "localhost",
+		"apiKeys": map[string]any{"key-id": 1},
+	}
+
+	arr := New(data, WithKeyNormalizer(SnakeCase))
+
+	assert(arr.Get("DBHost"), "localhost", "Get DBHost")
+	assert(arr.Get("db-host"), "localhost", "Get db-host")
+	assert(arr.Get("api_keys.keyId"), 1, "Get api_keys.keyId")
+	assert(arr.Exists("dbhost"), false, "Exists dbhost")
+	assert(arr.Sub("api-keys").Get("KeyID"), 1, "Sub inherits normalizer")
+
+	if _, err := arr.Set("127.0.0.1", "dbHost"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["db_host"], "127.0.0.1", "Set original spelling")
+	assert(len(data), 2, "Set no new key")
+
+	if _, err := arr.Set(2, "api_keys", "new_key"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["apiKeys"], map[string]any{"key-id": 1, "new_key": 2}, "Set new key")
+
+	if err := arr.Delete("ApiKeys", "KeyId"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["apiKeys"], map[string]any{"new_key": 2}, "Delete normalized key")
+
+	arr = New(map[string]any{"dbhost": 1}, WithKeyNormalizer(SnakeCase), WithCaseInsensitive(true))
+	assert(arr.Get("DBHOST"), 1, "Get with both modes")
+}
+
+type testKeyIP [4]byte
+
+func (this testKeyIP) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%d.%d.%d", this[0], this[1], this[2], this[3])), nil
+}
+
+func (this *testKeyIP) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d.%d.%d.%d", &this[0], &this[1], &this[2], &this[3])
+	return err
+}
+
+func Test_TypedMapKeys(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	ints := map[int]any{1: "a"}
+	uints := map[uint8]string{}
+	floats := map[float64]int{}
+	bools := map[bool]string{}
+	ips := map[testKeyIP]string{{127, 0, 0, 1}: "local"}
+
+	arr := New(map[string]any{
+		"b":  map[string]any{"hh": ints},
+		"u":  uints,
+		"f":  floats,
+		"t":  bools,
+		"ip": ips,
+	})
+
+	if _, err := arr.SetKey("x", "b.hh.1115"); err != nil {
+		t.Fatal(err)
+	}
+	assert(ints[1115], "x", "SetKey int key")
+	assert(arr.Get("b.hh.1115"), "x", "Get int key")
+
+	if _, err := arr.Set("y", "u", "255"); err != nil {
+		t.Fatal(err)
+	}
+	assert(uints[255], "y", "Set uint8 key")
+
+	_, err := arr.Set(65, "u", "1")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Set number to string value")
+	assert(len(uints), 1, "Set number to string value keeps map")
+
+	if _, err := arr.Set(2, "f", "1.5"); err != nil {
+		t.Fatal(err)
+	}
+	assert(floats[1.5], 2, "Set float key")
+
+	if _, err := arr.Set("yes", "t", "true"); err != nil {
+		t.Fatal(err)
+	}
+	assert(bools[true], "yes", "Set bool key")
+
+	if _, err := arr.Set("dns", "ip", "8.8.8.8"); err != nil {
+		t.Fatal(err)
+	}
+	assert(ips[testKeyIP{8, 8, 8, 8}], "dns", "Set TextUnmarshaler key")
+	assert(arr.Get("ip.127.0.0.1"), "local", "Get TextMarshaler key")
+	assert(New(ips, WithKeyDelim("/")).Get("8.8.8.8"), "dns", "Get TextMarshaler key with keyDelim")
+
+	if err := arr.Delete("b", "hh", "1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("b.hh"), map[int]any{1115: "x"}, "Delete int key")
+
+	testErrs := []struct {
+		path []any
+		msg  string
+	}{
+		{[]any{"u", "256"}, "set 'u.256': segment 1: type mismatch: key 256 overflows uint8"},
+		{[]any{"u", "-1"}, "set 'u.-1': segment 1: type mismatch: key -1 overflows uint8"},
+		{[]any{"b", "hh", "1.5"}, "set 'b.hh.1~15': segment 2: type mismatch: key 1.5 is not an integer"},
+		{[]any{"b", "hh", "x"}, "set 'b.hh.x': segment 2: type mismatch: key 'x' is not int"},
+		{[]any{"t", "maybe"}, "set 't.maybe': segment 1: type mismatch: key 'maybe' is not bool"},
+	}
+
+	for _, v := range testErrs {
+		_, err := arr.Set("v", v.path...)
+		assert(errors.Is(err, ErrTypeMismatch), true, "Set error cause "+v.msg)
+		if err != nil {
+			assert(err.Error(), v.msg, "Set error message")
+		}
+	}
+
+	err = arr.Delete("ip", "x")
+	assert(errors.Is(err, ErrTypeMismatch), true, "Delete TextUnmarshaler key error")
+
+	err = arr.Delete("b", "hh", "7")
+	assert(errors.Is(err, ErrNotFound), true, "Delete missing int key")
+
+	anys := map[any]any{1: "a", true: "t"}
+	arr2 := New(anys)
+
+	assert(arr2.Get("1"), "a", "Get interface key")
+
+	if _, err := arr2.Set("b", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr2.Set("f", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr2.Set("n", "2"); err != nil {
+		t.Fatal(err)
+	}
+	assert(anys, map[any]any{1: "b", true: "f", "2": "n"}, "Set interface key")
+
+	if err := arr2.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr2.Value(), map[any]any{true: "f", "2": "n"}, "Delete interface key")
+}
@@ -0,0 +1,342 @@
+package array
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// 切片合并策略
+// SliceStrategy is how slices are merged
+type SliceStrategy int
+
+const (
+	// 替换 / replace the slice
+	SliceReplace SliceStrategy = iota
+	// 追加 / append the elements
+	SliceAppend
+	// 去重追加 / append the elements not in the slice
+	SliceUnion
+	// 按索引合并 / merge the elements by index
+	SliceMergeIndex
+	// 按字段合并 / merge the map elements by a key field
+	SliceMergeKey
+)
+
+// 冲突策略
+// ConflictStrategy is how scalar conflicts are resolved
+type ConflictStrategy int
+
+const (
+	// 覆盖 / override with the new value
+	ConflictOverride ConflictStrategy = iota
+	// 保留 / keep the old value
+	ConflictKeep
+	// 报错 / return ErrMergeConflict
+	ConflictError
+)
+
+var (
+	ErrMergeConflict = errors.New("merge conflict")
+)
+
+type mergeRule struct {
+	key      string
+	pattern  []string
+	slice    *SliceStrategy
+	mergeKey string
+	conflict *ConflictStrategy
+}
+
+// 合并设置
+// merge options
+type mergeOptions struct {
+	rules []mergeRule
+}
+
+// 合并设置
+// MergeOption configures Merge
+type MergeOption func(*mergeOptions)
+
+// 设置切片合并策略
+// WithSliceStrategy sets the slice strategy
+func WithSliceStrategy(strategy SliceStrategy) MergeOption {
+	return WithPathSliceStrategy("**", strategy)
+}
+
+// 设置切片按字段合并
+// WithSliceMergeKey merges map elements of slices by the key field
+func WithSliceMergeKey(key string) MergeOption {
+	return WithPathSliceMergeKey("**", key)
+}
+
+// 设置冲突策略
+// WithConflictStrategy sets the scalar conflict strategy
+func WithConflictStrategy(strategy ConflictStrategy) MergeOption {
+	return WithPathConflictStrategy("**", strategy)
+}
+
+// 设置路径的切片合并策略，路径支持 `*` 和 `**`
+// WithPathSliceStrategy sets the slice strategy for the key path, the
+// path supports `*` and `**`
+func WithPathSliceStrategy(key string, strategy SliceStrategy) MergeOption {
+	return func(opts *mergeOptions) {
+		opts.rules = append(opts.rules, mergeRule{
+			key:   key,
+			slice: &strategy,
+		})
+	}
+}
+
+// 设置路径的切片按字段合并
+// WithPathSliceMergeKey merges map elements of slices at the key path by
+// the key field
+func WithPathSliceMergeKey(key string, field string) MergeOption {
+	strategy := SliceMergeKey
+
+	return func(opts *mergeOptions) {
+		opts.rules = append(opts.rules, mergeRule{
+			key:      key,
+			slice:    &strategy,
+			mergeKey: field,
+		})
+	}
+}
+
+// 设置路径的冲突策略
+// WithPathConflictStrategy sets the scalar conflict strategy for the key path
+func WithPathConflictStrategy(key string, strategy ConflictStrategy) MergeOption {
+	return func(opts *mergeOptions) {
+		opts.rules = append(opts.rules, mergeRule{
+			key:      key,
+			conflict: &strategy,
+		})
+	}
+}
+
+func (this *mergeOptions) sliceStrategy(path []string) (SliceStrategy, string) {
+	for i := len(this.rules) - 1; i >= 0; i-- {
+		rule := this.rules[i]
+		if rule.slice != nil && matchPathPattern(rule.pattern, path) {
+			return *rule.slice, rule.mergeKey
+		}
+	}
+
+	return SliceReplace, ""
+}
+
+func (this *mergeOptions) conflictStrategy(path []string) ConflictStrategy {
+	for i := len(this.rules) - 1; i >= 0; i-- {
+		rule := this.rules[i]
+		if rule.conflict != nil && matchPathPattern(rule.pattern, path) {
+			return *rule.conflict
+		}
+	}
+
+	return ConflictOverride
+}
+
+// 深度合并
+// Merge deep merges others into the Array in order, the values merged
+// before an error like ErrMergeConflict are kept
+func (this *Array) Merge(others []*Array, opts ...MergeOption) error {
+	this.refresh()
+
+	options := &mergeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	for i := range options.rules {
+		options.rules[i].pattern = KeyDelimPathToSlice(options.rules[i].key, this.keyDelim)
+	}
+
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+
+		if err := this.merge(nil, other.Value(), options); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (this *Array) merge(path []string, src any, opts *mergeOptions) error {
+	if !this.patchExists(path) || this.patchValue(path) == nil {
+		_, err := this.setPath(deepCopy(src), formatPath(path)...)
+		return err
+	}
+
+	dst := this.patchValue(path)
+
+	if srcMap, ok := this.anyDataMapFormat(src); ok {
+		if _, ok := this.anyDataMapFormat(dst); ok {
+			keys := make([]string, 0, len(srcMap))
+			for k := range srcMap {
+				keys = append(keys, k)
+			}
+
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				if err := this.merge(appendPath(path, k), srcMap[k], opts); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	if srcSlice, ok := this.anySliceFormat(src); ok {
+		if dstSlice, ok := this.anySliceFormat(dst); ok {
+			return this.mergeSlice(path, dstSlice, srcSlice, opts)
+		}
+	}
+
+	if this.queryEqual(dst, src) {
+		return nil
+	}
+
+	switch opts.conflictStrategy(path) {
+	case ConflictKeep:
+		return nil
+	case ConflictError:
+		return this.pathError("merge", formatPath(path), -1, ErrMergeConflict, "")
+	}
+
+	_, err := this.setPath(deepCopy(src), formatPath(path)...)
+	return err
+}
+
+func (this *Array) mergeSlice(path []string, dst, src []any, opts *mergeOptions) error {
+	strategy, mergeKey := opts.sliceStrategy(path)
+
+	switch strategy {
+	case SliceAppend:
+		return this.appendSlice(path, src)
+	case SliceUnion:
+		elems := make([]any, 0, len(src))
+		for _, v := range src {
+			if !this.sliceContains(dst, v) && !this.sliceContains(elems, v) {
+				elems = append(elems, v)
+			}
+		}
+
+		return this.appendSlice(path, elems)
+	case SliceMergeIndex:
+		for i, v := range src {
+			if i >= len(dst) {
+				return this.appendSlice(path, src[i:])
+			}
+
+			if err := this.merge(appendPath(path, toString(i)), v, opts); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case SliceMergeKey:
+		elems := make([]any, 0)
+		for _, v := range src {
+			index := this.sliceKeyIndex(dst, mergeKey, v)
+			if index < 0 {
+				elems = append(elems, v)
+				continue
+			}
+
+			if err := this.merge(appendPath(path, toString(index)), v, opts); err != nil {
+				return err
+			}
+		}
+
+		return this.appendSlice(path, elems)
+	}
+
+	_, err := this.setPath(deepCopy(src), formatPath(path)...)
+	return err
+}
+
+// 追加数据，保持原切片类型
+// append the elements to the slice at path, keeps the slice type
+func (this *Array) appendSlice(path []string, elems []any) error {
+	if len(elems) == 0 {
+		return nil
+	}
+
+	sourceValue := reflect.ValueOf(this.patchValue(path))
+	for sourceValue.Kind() == reflect.Pointer {
+		sourceValue = sourceValue.Elem()
+	}
+
+	var dstValue reflect.Value
+
+	switch sourceValue.Kind() {
+	case reflect.Slice:
+		dstValue = reflect.MakeSlice(sourceValue.Type(), 0, sourceValue.Len()+len(elems))
+		dstValue = reflect.AppendSlice(dstValue, sourceValue)
+	case reflect.Array:
+		dstValue = reflect.MakeSlice(reflect.SliceOf(sourceValue.Type().Elem()), 0, sourceValue.Len()+len(elems))
+		for i := 0; i < sourceValue.Len(); i++ {
+			dstValue = reflect.Append(dstValue, sourceValue.Index(i))
+		}
+
+		sourceValue = reflect.Value{}
+	default:
+		return this.pathError("merge", formatPath(path), -1, ErrTypeMismatch, "%T is not an array", this.patchValue(path))
+	}
+
+	for _, v := range elems {
+		valueValue, err := this.convertTo(dstValue.Type().Elem(), deepCopy(v))
+		if err != nil {
+			return this.pathError("merge", formatPath(path), -1, err, "")
+		}
+
+		dstValue = reflect.Append(dstValue, valueValue)
+	}
+
+	if !sourceValue.IsValid() || !sourceValue.CanSet() {
+		_, err := this.setPath(dstValue.Interface(), formatPath(path)...)
+		return err
+	}
+
+	sourceValue.Set(dstValue)
+	return nil
+}
+
+func (this *Array) sliceContains(slice []any, value any) bool {
+	for _, v := range slice {
+		if this.queryEqual(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 获取字段相同的元素索引
+// index of the map element which has the same key field
+func (this *Array) sliceKeyIndex(slice []any, key string, value any) int {
+	valueMap, ok := this.anyDataMapFormat(value)
+	if !ok {
+		return -1
+	}
+
+	id, ok := valueMap[key]
+	if !ok {
+		return -1
+	}
+
+	for i, v := range slice {
+		if m, ok := this.anyDataMapFormat(v); ok {
+			if vid, ok := m[key]; ok && this.queryEqual(vid, id) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,134 @@
+package array
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// 应用 JSON Merge Patch
+// MergePatch applies a RFC 7386 JSON Merge Patch, the patch can be JSON
+// bytes, an *Array or any map/slice data. null deletes the key, objects
+// merge recursively and everything else replaces.
+func (this *Array) MergePatch(patch any) error {
+	switch p := patch.(type) {
+	case []byte:
+		var data any
+		if err := json.Unmarshal(p, &data); err != nil {
+			return err
+		}
+
+		patch = data
+	case json.RawMessage:
+		var data any
+		if err := json.Unmarshal(p, &data); err != nil {
+			return err
+		}
+
+		patch = data
+	case *Array:
+		patch = p.Value()
+	}
+
+	this.refresh()
+
+	return this.mergePatch(nil, patch)
+}
+
+func (this *Array) mergePatch(path []string, patch any) error {
+	patchMap, ok := this.anyDataMapFormat(patch)
+	if !ok {
+		_, err := this.setPath(patch, formatPath(path)...)
+		return err
+	}
+
+	target := this.patchValue(path)
+	if _, ok := this.anyDataMapFormat(target); !ok {
+		if _, err := this.setPath(map[string]any{}, formatPath(path)...); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(patchMap))
+	for k := range patchMap {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		keyPath := appendPath(path, k)
+
+		if patchMap[k] == nil {
+			if this.patchExists(keyPath) {
+				if err := this.deletePath(formatPath(keyPath)...); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if err := this.mergePatch(keyPath, patchMap[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 生成 JSON Merge Patch
+// CreateMergePatch returns the RFC 7386 JSON Merge Patch that turns
+// original into modified
+func CreateMergePatch(original, modified *Array) ([]byte, error) {
+	patch := original.createMergePatch(original.Value(), modified.Value())
+
+	data, err := original.anyJSONFormat(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(data)
+}
+
+func (this *Array) createMergePatch(original, modified any) any {
+	modifiedMap, ok := this.anyDataMapFormat(modified)
+	if !ok {
+		return modified
+	}
+
+	originalMap, ok := this.anyDataMapFormat(original)
+	if !ok {
+		return modified
+	}
+
+	patch := make(map[string]any)
+	for k := range originalMap {
+		if _, ok := modifiedMap[k]; !ok {
+			patch[k] = nil
+		}
+	}
+
+	for k, v := range modifiedMap {
+		ov, ok := originalMap[k]
+		if !ok {
+			patch[k] = v
+			continue
+		}
+
+		_, isMap := this.anyDataMapFormat(ov)
+		_, isModifiedMap := this.anyDataMapFormat(v)
+		if isMap && isModifiedMap {
+			if sub := this.createMergePatch(ov, v).(map[string]any); len(sub) > 0 {
+				patch[k] = sub
+			}
+
+			continue
+		}
+
+		if !this.queryEqual(ov, v) {
+			patch[k] = v
+		}
+	}
+
+	return patch
+}
@@ -0,0 +1,108 @@
+package array
+
+import (
+	"testing"
+)
+
+var mergePatchTestData = []struct {
+	original string
+	patch    string
+	result   string
+}{
+	{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+	{`{"a":"b"}`, `{"a":null}`, `{}`},
+	{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+	{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+	{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+	{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+	{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+	{`{"a":"b"}`, `["c"]`, `["c"]`},
+	{`{"a":"foo"}`, `null`, `null`},
+	{`{"a":"foo"}`, `"bar"`, `"bar"`},
+	{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+	{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+	{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+}
+
+func Test_MergePatch(t *testing.T) {
+	assert := assertT(t)
+
+	for _, v := range mergePatchTestData {
+		arr, _ := ParseJSON([]byte(v.original))
+
+		if err := arr.MergePatch([]byte(v.patch)); err != nil {
+			t.Fatal(err)
+		}
+
+		assert(arr.String(), v.result, "MergePatch "+v.patch)
+	}
+
+	if err := New(nil).MergePatch([]byte(`{`)); err == nil {
+		t.Error("MergePatch should error on invalid json")
+	}
+}
+
+func Test_MergePatchTyped(t *testing.T) {
+	assert := assertT(t)
+
+	cfg := newTestStructConfig()
+
+	arr := New(map[string]any{
+		"cfg": cfg,
+		"m":   map[string]int{"a": 1, "b": 2},
+	})
+
+	patch := New(map[string]any{
+		"cfg": map[string]any{
+			"name": "merged",
+			"server": map[string]any{
+				"port": 9000,
+			},
+		},
+		"m": map[string]any{
+			"a": nil,
+			"c": 3,
+		},
+	})
+
+	if err := arr.MergePatch(patch); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(cfg.Name, "merged", "MergePatch struct")
+	assert(cfg.Server.Port, "9000", "MergePatch nested struct")
+	assert(cfg.Server.Host, "localhost", "MergePatch keep field")
+	assert(arr.Sub("m").String(), `{"b":2,"c":3}`, "MergePatch typed map")
+}
+
+func Test_CreateMergePatch(t *testing.T) {
+	assert := assertT(t)
+
+	for _, v := range mergePatchTestData {
+		original, _ := ParseJSON([]byte(v.original))
+		modified, _ := ParseJSON([]byte(v.result))
+
+		patch, err := CreateMergePatch(original, modified)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := original.MergePatch(patch); err != nil {
+			t.Fatal(err)
+		}
+
+		assert(original.String(), v.result, "CreateMergePatch "+v.original+" "+v.result)
+	}
+
+	original, _ := ParseJSON([]byte(`{"a":"b","c":{"d":"e","f":"g"},"h":[1]}`))
+	modified, _ := ParseJSON([]byte(`{"a":"z","c":{"d":"e"},"h":[1]}`))
+
+	patch, err := CreateMergePatch(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(patch, `{"a":"z","c":{"f":null}}`, "CreateMergePatch")
+}
@@ -0,0 +1,186 @@
+package array
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Merge(t *testing.T) {
+	assert := assertT(t)
+
+	mergeJSON := func(a string, others []string, opts ...MergeOption) (string, error) {
+		arr, _ := ParseJSON([]byte(a))
+
+		arrs := make([]*Array, 0, len(others))
+		for _, v := range others {
+			other, _ := ParseJSON([]byte(v))
+			arrs = append(arrs, other)
+		}
+
+		err := arr.Merge(arrs, opts...)
+		return arr.String(), err
+	}
+
+	testData := []struct {
+		a      string
+		others []string
+		opts   []MergeOption
+		result string
+	}{
+		{`{"a":1,"b":{"c":2}}`, []string{`{"b":{"d":3}}`, `{"e":4}`}, nil, `{"a":1,"b":{"c":2,"d":3},"e":4}`},
+		{`{"a":1}`, []string{`{"a":2}`}, nil, `{"a":2}`},
+		{`{"a":1}`, []string{`{"a":2}`}, []MergeOption{WithConflictStrategy(ConflictKeep)}, `{"a":1}`},
+		{`{"a":null}`, []string{`{"a":2}`}, []MergeOption{WithConflictStrategy(ConflictKeep)}, `{"a":2}`},
+		{`{"a":[1,2]}`, []string{`{"a":[2,3]}`}, nil, `{"a":[2,3]}`},
+		{`{"a":[1,2]}`, []string{`{"a":[2,3]}`}, []MergeOption{WithSliceStrategy(SliceAppend)}, `{"a":[1,2,2,3]}`},
+		{`{"a":[1,2]}`, []string{`{"a":[2,3,3]}`}, []MergeOption{WithSliceStrategy(SliceUnion)}, `{"a":[1,2,3]}`},
+		{`{"a":[{"x":1},{"x":2}]}`, []string{`{"a":[{"y":1},{"y":2},{"y":3}]}`}, []MergeOption{WithSliceStrategy(SliceMergeIndex)}, `{"a":[{"x":1,"y":1},{"x":2,"y":2},{"y":3}]}`},
+		{
+			`{"a":[{"id":1,"v":"a"},{"id":2,"v":"b"}]}`,
+			[]string{`{"a":[{"id":2,"v":"c"},{"id":3,"v":"d"}]}`},
+			[]MergeOption{WithSliceMergeKey("id")},
+			`{"a":[{"id":1,"v":"a"},{"id":2,"v":"c"},{"id":3,"v":"d"}]}`,
+		},
+		{
+			`{"a":[1],"b":[1],"c":{"d":[1]}}`,
+			[]string{`{"a":[2],"b":[2],"c":{"d":[2]}}`},
+			[]MergeOption{WithPathSliceStrategy("a", SliceAppend), WithPathSliceStrategy("*.d", SliceAppend)},
+			`{"a":[1,2],"b":[2],"c":{"d":[1,2]}}`,
+		},
+		{
+			`{"a":1,"b":1}`,
+			[]string{`{"a":2,"b":2}`},
+			[]MergeOption{WithConflictStrategy(ConflictKeep), WithPathConflictStrategy("b", ConflictOverride)},
+			`{"a":1,"b":2}`,
+		},
+	}
+
+	for _, v := range testData {
+		res, err := mergeJSON(v.a, v.others, v.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert(res, v.result, "Merge "+v.a)
+	}
+
+	_, err := mergeJSON(`{"a":{"b":1}}`, []string{`{"a":{"b":2}}`}, WithConflictStrategy(ConflictError))
+	if !errors.Is(err, ErrMergeConflict) {
+		t.Errorf("Merge conflict error got %v", err)
+	}
+
+	_, err = mergeJSON(`{"a":{"b":1}}`, []string{`{"a":{"b":1}}`}, WithConflictStrategy(ConflictError))
+	if err != nil {
+		t.Errorf("Merge equal values should not conflict, got %v", err)
+	}
+}
+
+func Test_MergeTyped(t *testing.T) {
+	assert := assertT(t)
+
+	arr := New(map[any]any{
+		"ints":  map[string]int64{"a": 1},
+		"list":  []int{1, 2},
+		"names": []string{"a"},
+	})
+
+	other := New(map[string]any{
+		"ints":  map[string]any{"b": 2.0},
+		"list":  []any{3.0},
+		"names": []any{"a", "b"},
+	})
+
+	err := arr.Merge([]*Array{other}, WithPathSliceStrategy("list", SliceAppend), WithPathSliceStrategy("names", SliceUnion))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr.String(), `{"ints":{"a":1,"b":2},"list":[1,2,3],"names":["a","b"]}`, "MergeTyped")
+
+	if _, ok := arr.Get("list").([]int); !ok {
+		t.Errorf("MergeTyped list type got %T", arr.Get("list"))
+	}
+	if _, ok := arr.Get("ints").(map[string]int64); !ok {
+		t.Errorf("MergeTyped ints type got %T", arr.Get("ints"))
+	}
+
+	err = arr.Merge([]*Array{New(map[string]any{"ints": map[string]any{"c": "x"}})})
+	if err == nil {
+		t.Error("MergeTyped should error on value that can not be converted")
+	}
+}
+
+func Test_MergeInterfaceKeys(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	data := map[any]any{
+		1:   "a",
+		"n": map[any]any{2: "x"},
+	}
+
+	arr := New(data)
+	err := arr.Merge([]*Array{New(map[any]any{1: "b", "n": map[int]any{2: "y", 3: "z"}})})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(data, map[any]any{1: "b", "n": map[any]any{2: "y", "3": "z"}}, "Merge interface keys")
+
+	if err := arr.ApplyPatch([]byte(`[{"op":"replace","path":"/1","value":"c"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	assert(data[1], "c", "ApplyPatch interface key")
+
+	if err := arr.MergePatch([]byte(`{"1":"d","n":{"2":null}}`)); err != nil {
+		t.Fatal(err)
+	}
+	assert(data[1], "d", "MergePatch interface key")
+	assert(arr.Get("n"), map[any]any{"3": "z"}, "MergePatch delete interface key")
+
+	if _, err := arr.SetPointer("e", "/1"); err != nil {
+		t.Fatal(err)
+	}
+	assert(len(data), 2, "SetPointer interface key len")
+	assert(data[1], "e", "SetPointer interface key")
+}
+
+func Test_MergeConflictPropagate(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type conf struct {
+		A int    `json:"a"`
+		B string `json:"b"`
+	}
+
+	data := map[string]any{
+		"conf": conf{B: "x"},
+	}
+
+	sub := New(data).Sub("conf")
+
+	err := sub.Merge([]*Array{New(map[string]any{"a": 1, "b": "y"})}, WithPathConflictStrategy("b", ConflictError))
+	if !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("Merge conflict error got %v", err)
+	}
+
+	assert(data["conf"], conf{A: 1, B: "x"}, "Merge before conflict is written back")
+}
+
+func Test_MergeCycle(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	n := newTestStructCycle()
+
+	arr := New(map[string]any{})
+	if err := arr.Merge([]*Array{New(map[string]any{"node": n})}); err != nil {
+		t.Fatal(err)
+	}
+
+	node, ok := arr.Get("node").(*testStructNode)
+	if !ok {
+		t.Fatalf("Merge cycle got %T", arr.Get("node"))
+	}
+
+	assert(node != n, true, "Merge cycle copies")
+	assert(node.Parent == node, true, "Merge cycle keeps cycle")
+}
@@ -0,0 +1,277 @@
+package array
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+)
+
+// 基础类型
+// unnamed types of the basic kinds
+var basicKindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:       reflect.TypeOf(false),
+	reflect.Int:        reflect.TypeOf(int(0)),
+	reflect.Int8:       reflect.TypeOf(int8(0)),
+	reflect.Int16:      reflect.TypeOf(int16(0)),
+	reflect.Int32:      reflect.TypeOf(int32(0)),
+	reflect.Int64:      reflect.TypeOf(int64(0)),
+	reflect.Uint:       reflect.TypeOf(uint(0)),
+	reflect.Uint8:      reflect.TypeOf(uint8(0)),
+	reflect.Uint16:     reflect.TypeOf(uint16(0)),
+	reflect.Uint32:     reflect.TypeOf(uint32(0)),
+	reflect.Uint64:     reflect.TypeOf(uint64(0)),
+	reflect.Uintptr:    reflect.TypeOf(uintptr(0)),
+	reflect.Float32:    reflect.TypeOf(float32(0)),
+	reflect.Float64:    reflect.TypeOf(float64(0)),
+	reflect.Complex64:  reflect.TypeOf(complex64(0)),
+	reflect.Complex128: reflect.TypeOf(complex128(0)),
+	reflect.String:     reflect.TypeOf(""),
+}
+
+// 标准化设置
+// normalize options
+type normalizeOptions struct {
+	tagName string
+
+	// 循环引用检测 / cycle detection of the walk
+	seen cycleDetector
+}
+
+// 标准化设置
+// NormalizeOption configures FromStruct
+type NormalizeOption func(*normalizeOptions)
+
+// 设置结构体标签，默认为 json
+// WithNormalizeTagName sets the struct tag name, the default is json
+func WithNormalizeTagName(tagName string) NormalizeOption {
+	return func(opts *normalizeOptions) {
+		opts.tagName = tagName
+	}
+}
+
+// 从结构体创建
+// FromStruct converts any value to a map[string]any and []any tree.
+// Struct fields use json tags by default, json.Marshaler and
+// encoding.TextMarshaler values are marshaled, map keys are converted
+// with toString and named basic types become their unnamed types.
+// Cyclic data returns an error wrapping ErrCycle.
+func FromStruct(v any, opts ...NormalizeOption) (*Array, error) {
+	options := &normalizeOptions{
+		tagName: defaultTagName,
+		seen:    cycleDetector{},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	data, err := normalizeValue(reflect.ValueOf(v), options)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(data), nil
+}
+
+// 标准化数据
+// Normalize returns a new Array with the data converted to a
+// map[string]any and []any tree, see FromStruct
+func (this *Array) Normalize() (*Array, error) {
+	options := &normalizeOptions{
+		tagName: this.tagName,
+		seen:    cycleDetector{},
+	}
+
+	data, err := normalizeValue(reflect.ValueOf(this.source), options)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.derive(data), nil
+}
+
+func normalizeValue(v reflect.Value, opts *normalizeOptions) (any, error) {
+	for v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, nil
+	}
+
+	if data, ok := normalizeMarshaler(v); ok {
+		return data, nil
+	}
+
+	if !opts.seen.enter(v) {
+		return nil, cycleError(v)
+	}
+	defer opts.seen.leave(v)
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		return normalizeValue(v.Elem(), opts)
+	case reflect.Struct:
+		m := make(map[string]any)
+
+		for _, f := range getStructFields(v.Type(), opts.tagName) {
+			fv, ok := structFieldValue(v, f.index, false)
+			if !ok {
+				continue
+			}
+
+			if f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+
+			value, err := normalizeValue(fv, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			m[f.name] = value
+		}
+
+		return m, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		m := make(map[string]any, v.Len())
+
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := normalizeMapKey(iter.Key(), opts)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := normalizeValue(iter.Value(), opts)
+			if err != nil {
+				return nil, err
+			}
+
+			m[key] = value
+		}
+
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return nil, nil
+			}
+
+			// []byte 作为值使用
+			// []byte is a leaf
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				return v.Bytes(), nil
+			}
+		}
+
+		s := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			value, err := normalizeValue(v.Index(i), opts)
+			if err != nil {
+				return nil, err
+			}
+
+			s[i] = value
+		}
+
+		return s, nil
+	}
+
+	if typ, ok := basicKindTypes[v.Kind()]; ok && v.Type() != typ {
+		return v.Convert(typ).Interface(), nil
+	}
+
+	return v.Interface(), nil
+}
+
+// map 键名转为字符串，和 encoding/json 一样字符串类型的直接使用
+// convert the map key to string, keys of string kind are used directly
+// like encoding/json
+func normalizeMapKey(k reflect.Value, opts *normalizeOptions) (string, error) {
+	for k.Kind() == reflect.Interface && !k.IsNil() {
+		k = k.Elem()
+	}
+
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+
+	if data, ok := normalizeMarshaler(k); ok {
+		if s, ok := data.(string); ok {
+			return s, nil
+		}
+	}
+
+	value, err := normalizeValue(k, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return toString(value), nil
+}
+
+// 使用 json.Marshaler 或者 encoding.TextMarshaler 转换
+// marshal the value with json.Marshaler or encoding.TextMarshaler
+func normalizeMarshaler(v reflect.Value) (any, bool) {
+	if !isMarshalerType(v.Type()) {
+		return nil, false
+	}
+
+	if !v.CanAddr() {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		v = ptr.Elem()
+	}
+
+	var data any
+	switch {
+	case v.Type().Implements(jsonMarshalerType):
+		data = v.Interface()
+	case v.Addr().Type().Implements(jsonMarshalerType):
+		data = v.Addr().Interface()
+	}
+
+	if m, ok := data.(json.Marshaler); ok {
+		b, err := m.MarshalJSON()
+		if err != nil {
+			return nil, false
+		}
+
+		var res any
+		if err := json.Unmarshal(b, &res); err != nil {
+			return nil, false
+		}
+
+		return res, true
+	}
+
+	switch {
+	case v.Type().Implements(textMarshalerType):
+		data = v.Interface()
+	case v.Addr().Type().Implements(textMarshalerType):
+		data = v.Addr().Interface()
+	}
+
+	if m, ok := data.(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return nil, false
+		}
+
+		return string(b), true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,107 @@
+package array
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testNormalizeLevel int
+
+type testNormalizeName string
+
+func (this testNormalizeName) MarshalText() ([]byte, error) {
+	return []byte("name:" + string(this)), nil
+}
+
+type testNormalizeItem struct {
+	ID    int                `json:"id"`
+	Level testNormalizeLevel `json:"level"`
+	Skip  string             `json:"-"`
+	Empty string             `json:"empty,omitempty"`
+	Other string             `yaml:"other_name"`
+}
+
+type testNormalizeData struct {
+	Name    testNormalizeName         `json:"name"`
+	Items   []testNormalizeItem       `json:"items"`
+	Codes   map[int]any               `json:"codes"`
+	Pair    [2]int                    `json:"pair"`
+	Ptr     *testNormalizeItem        `json:"ptr"`
+	Nil     *testNormalizeItem        `json:"nil"`
+	Any     map[any]any               `json:"any"`
+	At      time.Time                 `json:"at"`
+	Bytes   []byte                    `json:"bytes"`
+	Entries map[testNormalizeName]int `json:"entries"`
+}
+
+func Test_FromStruct(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	fromStruct := func(v any, opts ...NormalizeOption) any {
+		arr, err := FromStruct(v, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return arr.Value()
+	}
+
+	data := testNormalizeData{
+		Name:    "n",
+		Items:   []testNormalizeItem{{ID: 1, Level: 2, Skip: "s", Other: "o"}},
+		Codes:   map[int]any{200: "ok"},
+		Pair:    [2]int{3, 4},
+		Ptr:     &testNormalizeItem{ID: 5},
+		Any:     map[any]any{1: []int{6}},
+		At:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Bytes:   []byte("b"),
+		Entries: map[testNormalizeName]int{"k": 7},
+	}
+
+	expected := map[string]any{
+		"name":    "name:n",
+		"items":   []any{map[string]any{"id": 1, "level": 2, "Other": "o"}},
+		"codes":   map[string]any{"200": "ok"},
+		"pair":    []any{3, 4},
+		"ptr":     map[string]any{"id": 5, "level": 0, "Other": ""},
+		"nil":     nil,
+		"any":     map[string]any{"1": []any{6}},
+		"at":      "2024-01-02T03:04:05Z",
+		"bytes":   []byte("b"),
+		"entries": map[string]any{"k": 7},
+	}
+
+	assert(fromStruct(data), expected, "FromStruct")
+	assert(fromStruct(&data), expected, "FromStruct pointer")
+
+	normalized, err := New(data).Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(normalized.Value(), expected, "Normalize")
+
+	assert(
+		fromStruct(testNormalizeItem{ID: 1, Other: "o"}, WithNormalizeTagName("yaml")),
+		map[string]any{"ID": 1, "Level": 0, "Skip": "", "Empty": "", "other_name": "o"},
+		"FromStruct tag name",
+	)
+
+	assert(fromStruct(nil), nil, "FromStruct nil")
+	assert(fromStruct(testNormalizeLevel(3)), 3, "FromStruct named scalar")
+}
+
+func Test_FromStructCycle(t *testing.T) {
+	if _, err := FromStruct(newTestStructCycle()); !errors.Is(err, ErrCycle) {
+		t.Errorf("FromStruct cycle got %v", err)
+	}
+
+	if _, err := New(map[string]any{"n": newTestStructCycle()}).Normalize(); !errors.Is(err, ErrCycle) {
+		t.Errorf("Normalize cycle got %v", err)
+	}
+
+	shared := &testStructNode{Name: "s"}
+	if _, err := FromStruct([]any{shared, shared}); err != nil {
+		t.Errorf("FromStruct shared pointer got %v", err)
+	}
+}
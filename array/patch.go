@@ -0,0 +1,415 @@
+package array
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// JSON Patch 操作
+// PatchOperation is a RFC 6902 JSON Patch operation
+type PatchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value any
+}
+
+// 返回 JSON 数据
+// MarshalJSON returns the operation as a JSON Patch object
+func (this PatchOperation) MarshalJSON() ([]byte, error) {
+	data := map[string]any{
+		"op":   this.Op,
+		"path": this.Path,
+	}
+
+	switch this.Op {
+	case "move", "copy":
+		data["from"] = this.From
+	case "add", "replace", "test":
+		value, err := New(this.Value).anyJSONFormat(this.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		data["value"] = value
+	}
+
+	return json.Marshal(data)
+}
+
+// JSON Patch 错误
+// PatchError records the failed JSON Patch operation
+type PatchError struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("json patch operation %d '%s' on '%s': %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrPatchTestFailed = errors.New("test operation failed")
+)
+
+// 解析 JSON Patch
+// ParsePatch parses a RFC 6902 JSON Patch document
+func ParsePatch(patch []byte) ([]PatchOperation, error) {
+	var docs []map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &docs); err != nil {
+		return nil, err
+	}
+
+	ops := make([]PatchOperation, 0, len(docs))
+	for i, doc := range docs {
+		var op PatchOperation
+
+		if err := unmarshalPatchMember(doc, "op", &op.Op); err != nil {
+			return nil, &PatchError{i, op.Op, op.Path, err}
+		}
+		if err := unmarshalPatchMember(doc, "path", &op.Path); err != nil {
+			return nil, &PatchError{i, op.Op, op.Path, err}
+		}
+
+		switch op.Op {
+		case "add", "replace", "test":
+			if err := unmarshalPatchMember(doc, "value", &op.Value); err != nil {
+				return nil, &PatchError{i, op.Op, op.Path, err}
+			}
+		case "move", "copy":
+			if err := unmarshalPatchMember(doc, "from", &op.From); err != nil {
+				return nil, &PatchError{i, op.Op, op.Path, err}
+			}
+		case "remove":
+		default:
+			return nil, &PatchError{i, op.Op, op.Path, errors.New("unknown operation")}
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+func unmarshalPatchMember(doc map[string]json.RawMessage, name string, dst any) error {
+	raw, ok := doc[name]
+	if !ok {
+		return fmt.Errorf("missing member '%s'", name)
+	}
+
+	return json.Unmarshal(raw, dst)
+}
+
+// 应用 JSON Patch，失败时回滚
+// ApplyPatch applies a RFC 6902 JSON Patch document, all operations are
+// rolled back when one of them fails
+func (this *Array) ApplyPatch(patch []byte) error {
+	ops, err := ParsePatch(patch)
+	if err != nil {
+		return err
+	}
+
+	return this.ApplyPatchOperations(ops)
+}
+
+// 应用 JSON Patch 操作，失败时回滚
+// ApplyPatchOperations applies the operations, all operations are rolled
+// back when one of them fails
+func (this *Array) ApplyPatchOperations(ops []PatchOperation) error {
+	this.refresh()
+
+	restores := make([]func(), 0)
+
+	for i, op := range ops {
+		if err := this.applyPatchOperation(op, &restores); err != nil {
+			for j := len(restores) - 1; j >= 0; j-- {
+				restores[j]()
+			}
+
+			return &PatchError{i, op.Op, op.Path, err}
+		}
+	}
+
+	return nil
+}
+
+func (this *Array) applyPatchOperation(op PatchOperation, restores *[]func()) error {
+	path, err := JSONPointerToSlice(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add":
+		*restores = append(*restores, this.patchSnapshot(path))
+		return this.patchAdd(path, op.Value)
+	case "remove":
+		if !this.patchExists(path) {
+			return this.patchNotFound(path, "")
+		}
+
+		*restores = append(*restores, this.patchSnapshot(path))
+		return this.deletePath(formatPath(path)...)
+	case "replace":
+		if !this.patchExists(path) {
+			return this.patchNotFound(path, "")
+		}
+
+		*restores = append(*restores, this.patchSnapshot(path))
+		_, err := this.setPath(op.Value, formatPath(path)...)
+		return err
+	case "move", "copy":
+		from, err := JSONPointerToSlice(op.From)
+		if err != nil {
+			return err
+		}
+
+		if !this.patchExists(from) {
+			return this.patchNotFound(from, "from")
+		}
+
+		value := this.patchValue(from)
+
+		if op.Op == "move" {
+			if len(from) < len(path) && isPathPrefix(from, path) {
+				return this.pathError("patch", formatPath(path), -1, ErrInvalidPath, "from '%s' is a prefix of path", op.From)
+			}
+
+			*restores = append(*restores, this.patchSnapshot(from))
+			if err := this.deletePath(formatPath(from)...); err != nil {
+				return err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+
+		*restores = append(*restores, this.patchSnapshot(path))
+		return this.patchAdd(path, value)
+	case "test":
+		if !this.patchExists(path) {
+			return this.patchNotFound(path, "")
+		}
+
+		if !this.queryEqual(this.patchValue(path), op.Value) {
+			return this.pathError("patch", formatPath(path), -1, ErrPatchTestFailed, "")
+		}
+
+		return nil
+	}
+
+	return this.pathError("patch", formatPath(path), -1, errors.New("unknown operation"), "")
+}
+
+// 路径不存在错误
+// returns a PathError of the path that is not found
+func (this *Array) patchNotFound(path []string, format string, args ...any) error {
+	return this.pathError("patch", formatPath(path), this.missingSegment(path), ErrNotFound, format, args...)
+}
+
+// 添加数据，数组时插入
+// add the value, inserts into arrays
+func (this *Array) patchAdd(path []string, value any) error {
+	if len(path) == 0 {
+		_, err := this.setPath(value)
+		return err
+	}
+
+	parentPath := path[:len(path)-1]
+	if len(parentPath) > 0 && !this.patchExists(parentPath) {
+		return this.patchNotFound(path, "parent path")
+	}
+
+	parent := this.patchValue(parentPath)
+	if _, ok := this.anySliceFormat(parent); !ok {
+		_, err := this.setPath(value, formatPath(path)...)
+		return err
+	}
+
+	key := path[len(path)-1]
+	if key == "-" {
+		_, err := this.setPath(value, formatPath(path)...)
+		return err
+	}
+
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return this.pathError("patch", formatPath(path), len(path)-1, ErrInvalidIndex, "'%s' is not an array index", key)
+	}
+
+	return this.insertIndex(value, index, path)
+}
+
+// 插入数组数据，path 的最后一个为索引
+// insert the value into the array at path, the last segment of path is
+// the index
+func (this *Array) insertIndex(value any, index int, path []string) error {
+	last := len(path) - 1
+	parentPath := path[:last]
+
+	sourceValue := reflect.ValueOf(this.patchValue(parentPath))
+	for sourceValue.Kind() == reflect.Pointer {
+		sourceValue = sourceValue.Elem()
+	}
+
+	if sourceValue.Kind() != reflect.Slice {
+		return this.pathError("patch", formatPath(path), last, ErrTypeMismatch, "%T is not an array", this.patchValue(parentPath))
+	}
+
+	if index < 0 || index > sourceValue.Len() {
+		return this.pathError("patch", formatPath(path), last, ErrOutOfBounds, "index %d exceeds array size %d", index, sourceValue.Len())
+	}
+
+	valueValue, err := this.convertTo(sourceValue.Type().Elem(), value)
+	if err != nil {
+		return this.pathError("patch", formatPath(path), last, err, "")
+	}
+
+	dstValue := reflect.MakeSlice(sourceValue.Type(), 0, sourceValue.Len()+1)
+	dstValue = reflect.AppendSlice(dstValue, sourceValue.Slice(0, index))
+	dstValue = reflect.Append(dstValue, valueValue)
+	dstValue = reflect.AppendSlice(dstValue, sourceValue.Slice(index, sourceValue.Len()))
+
+	return this.writeBack(sourceValue, dstValue, parentPath)
+}
+
+// 获取数据
+// get the raw value at path
+func (this *Array) patchValue(path []string) any {
+	if len(path) == 0 {
+		return this.source
+	}
+
+	return this.Search(path...).Value()
+}
+
+// 判断路径是否存在，支持 null 数据
+// if the path exists, values can be null
+func (this *Array) patchExists(path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+
+	if len(path) > 1 && !this.patchExists(path[:len(path)-1]) {
+		return false
+	}
+
+	key := path[len(path)-1]
+
+	switch parent := this.anyDataFormat(this.patchValue(path[:len(path)-1])).(type) {
+	case map[string]any:
+		_, ok := parent[key]
+		return ok
+	case []any:
+		index, err := strconv.Atoi(key)
+		return err == nil && index >= 0 && index < len(parent)
+	}
+
+	return false
+}
+
+// 保存父级数据用于回滚
+// snapshot the parent of path for rollback
+func (this *Array) patchSnapshot(path []string) func() {
+	if len(path) == 0 {
+		source := this.source
+		return func() {
+			this.setPath(source)
+		}
+	}
+
+	parentPath := path[:len(path)-1]
+	parent := this.patchValue(parentPath)
+
+	sourceValue := reflect.ValueOf(parent)
+	for sourceValue.Kind() == reflect.Pointer {
+		sourceValue = sourceValue.Elem()
+	}
+
+	switch sourceValue.Kind() {
+	case reflect.Map:
+		if sourceValue.IsNil() {
+			break
+		}
+
+		copyValue := reflect.MakeMapWithSize(sourceValue.Type(), sourceValue.Len())
+
+		iter := sourceValue.MapRange()
+		for iter.Next() {
+			copyValue.SetMapIndex(iter.Key(), iter.Value())
+		}
+
+		// map 原地恢复
+		// restore the map in place
+		return func() {
+			for _, k := range sourceValue.MapKeys() {
+				sourceValue.SetMapIndex(k, reflect.Value{})
+			}
+
+			iter := copyValue.MapRange()
+			for iter.Next() {
+				sourceValue.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+	case reflect.Slice:
+		if sourceValue.IsNil() {
+			break
+		}
+
+		headerValue := reflect.ValueOf(sourceValue.Interface())
+
+		copyValue := reflect.MakeSlice(sourceValue.Type(), sourceValue.Len(), sourceValue.Len())
+		reflect.Copy(copyValue, sourceValue)
+
+		// 恢复原切片数据并回写
+		// restore the elements of the slice and write it back
+		return func() {
+			reflect.Copy(headerValue, copyValue)
+
+			if len(parentPath) == 0 && !sourceValue.CanSet() {
+				this.setPath(headerValue.Interface())
+				return
+			}
+
+			this.writeBack(sourceValue, headerValue, parentPath)
+		}
+	case reflect.Array, reflect.Struct:
+		copyValue := reflect.New(sourceValue.Type()).Elem()
+		copyValue.Set(sourceValue)
+
+		return func() {
+			if len(parentPath) == 0 && !sourceValue.CanSet() {
+				this.setPath(copyValue.Interface())
+				return
+			}
+
+			this.writeBack(sourceValue, copyValue, parentPath)
+		}
+	}
+
+	return func() {
+		this.setPath(parent, formatPath(parentPath)...)
+	}
+}
+
+func isPathPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
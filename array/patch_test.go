@@ -0,0 +1,215 @@
+package array
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_ApplyPatch(t *testing.T) {
+	assert := assertT(t)
+
+	testData := []struct {
+		name   string
+		source string
+		patch  string
+		check  string
+	}{
+		{
+			"add object member",
+			`{"foo":"bar"}`,
+			`[{"op":"add","path":"/baz","value":"qux"}]`,
+			`{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			"add array element",
+			`{"foo":["bar","baz"]}`,
+			`[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			`{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			"add array append",
+			`{"foo":["bar"]}`,
+			`[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			`{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			"add null value",
+			`{"foo":"bar"}`,
+			`[{"op":"add","path":"/baz","value":null}]`,
+			`{"baz":null,"foo":"bar"}`,
+		},
+		{
+			"remove",
+			`{"baz":"qux","foo":"bar"}`,
+			`[{"op":"remove","path":"/baz"}]`,
+			`{"foo":"bar"}`,
+		},
+		{
+			"remove array element",
+			`{"foo":["bar","qux","baz"]}`,
+			`[{"op":"remove","path":"/foo/1"}]`,
+			`{"foo":["bar","baz"]}`,
+		},
+		{
+			"replace",
+			`{"baz":"qux","foo":"bar"}`,
+			`[{"op":"replace","path":"/baz","value":"boo"}]`,
+			`{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			"move",
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			"move array element",
+			`{"foo":["all","grass","cows","eat"]}`,
+			`[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			`{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			"copy",
+			`{"foo":{"bar":[1]}}`,
+			`[{"op":"copy","from":"/foo/bar","path":"/baz"},{"op":"add","path":"/baz/-","value":2}]`,
+			`{"baz":[1,2],"foo":{"bar":[1]}}`,
+		},
+		{
+			"test",
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			"replace root",
+			`{"foo":"bar"}`,
+			`[{"op":"replace","path":"","value":[1]}]`,
+			`[1]`,
+		},
+		{
+			"root array",
+			`[1,2]`,
+			`[{"op":"add","path":"/0","value":0},{"op":"remove","path":"/2"}]`,
+			`[0,1]`,
+		},
+	}
+
+	for _, v := range testData {
+		t.Run(v.name, func(t *testing.T) {
+			arr, err := ParseJSON([]byte(v.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := arr.ApplyPatch([]byte(v.patch)); err != nil {
+				t.Fatal(err)
+			}
+
+			assert(arr.String(), v.check, v.name)
+		})
+	}
+}
+
+func Test_ApplyPatchError(t *testing.T) {
+	assert := assertT(t)
+
+	testData := []struct {
+		name  string
+		patch string
+	}{
+		{"test failed", `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{"remove not found", `[{"op":"remove","path":"/nothing"}]`},
+		{"replace not found", `[{"op":"replace","path":"/nothing","value":1}]`},
+		{"add parent not found", `[{"op":"add","path":"/a/b/c","value":1}]`},
+		{"add out of bounds", `[{"op":"add","path":"/foo/5","value":1}]`},
+		{"move into itself", `[{"op":"move","from":"/obj","path":"/obj/child"}]`},
+		{"unknown op", `[{"op":"nothing","path":"/baz"}]`},
+		{"missing value", `[{"op":"add","path":"/baz"}]`},
+		{"invalid pointer", `[{"op":"add","path":"baz","value":1}]`},
+		{"replace then fail", `[{"op":"replace","path":"/foo/1","value":5},{"op":"test","path":"/baz","value":"bar"}]`},
+		{"invalid json", `{`},
+	}
+
+	source := `{"baz":"qux","foo":[1,2],"obj":{"x":1}}`
+
+	for _, v := range testData {
+		t.Run(v.name, func(t *testing.T) {
+			arr, _ := ParseJSON([]byte(source))
+
+			patch := `[{"op":"add","path":"/new","value":1},{"op":"remove","path":"/foo/0"},{"op":"replace","path":"/obj/x","value":2}]`
+			if err := arr.ApplyPatch([]byte(patch[:len(patch)-1] + "," + v.patch[1:])); err == nil && v.name != "invalid json" {
+				t.Fatal("ApplyPatch should error")
+			}
+
+			assert(arr.String(), source, "rollback "+v.name)
+		})
+	}
+
+	arr, _ := ParseJSON([]byte(source))
+	err := arr.ApplyPatch([]byte(`[{"op":"test","path":"/baz","value":"bar"}]`))
+	if !errors.Is(err, ErrPatchTestFailed) {
+		t.Errorf("ApplyPatch should return ErrPatchTestFailed, got %v", err)
+	}
+}
+
+func Test_ApplyPatchTyped(t *testing.T) {
+	assert := assertT(t)
+
+	cfg := newTestStructConfig()
+
+	data := map[string]any{
+		"list":  []int64{1, 2, 3},
+		"ptr":   &[]float64{1.5},
+		"typed": map[int]any{1: "a"},
+		"cfg":   cfg,
+	}
+
+	arr := New(data)
+
+	patch := `[
+		{"op":"add","path":"/list/1","value":9},
+		{"op":"remove","path":"/ptr/0"},
+		{"op":"replace","path":"/cfg/name","value":"patched"},
+		{"op":"copy","from":"/list/0","path":"/list/-"}
+	]`
+
+	if err := arr.ApplyPatch([]byte(patch)); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr.Sub("list").String(), `[1,9,2,3,1]`, "typed slice")
+	assert(arr.Sub("ptr").String(), `[]`, "pointer slice")
+	assert(cfg.Name, "patched", "struct field")
+
+	// 回滚
+	err := arr.ApplyPatch([]byte(`[
+		{"op":"remove","path":"/list/0"},
+		{"op":"add","path":"/typed/2","value":"b"},
+		{"op":"replace","path":"/cfg/name","value":"rollback"},
+		{"op":"test","path":"/list/0","value":100}
+	]`))
+	if err == nil {
+		t.Fatal("ApplyPatch should error")
+	}
+
+	assert(arr.Sub("list").String(), `[1,9,2,3,1]`, "rollback typed slice")
+	assert(arr.Sub("typed").String(), `{"1":"a"}`, "rollback typed map")
+	assert(cfg.Name, "patched", "rollback struct field")
+}
+
+func Test_PatchOperationMarshalJSON(t *testing.T) {
+	assert := assertT(t)
+
+	ops := []PatchOperation{
+		{Op: "add", Path: "/a", Value: nil},
+		{Op: "move", Path: "/b", From: "/a"},
+		{Op: "remove", Path: "/b"},
+	}
+
+	data, err := New(ops).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(data, `[{"op":"add","path":"/a","value":null},{"from":"/a","op":"move","path":"/b"},{"op":"remove","path":"/b"}]`, "MarshalJSON")
+}
@@ -0,0 +1,105 @@
+package array
+
+import (
+	"fmt"
+)
+
+// JSON Pointer 错误
+// PointerError records a failed JSON Pointer operation and the pointer
+// prefix where it failed.
+type PointerError struct {
+	Op      string
+	Pointer string
+	Prefix  string
+	Err     error
+}
+
+func (e *PointerError) Error() string {
+	return fmt.Sprintf("json pointer %s '%s': failed at '%s': %v", e.Op, e.Pointer, e.Prefix, e.Err)
+}
+
+func (e *PointerError) Unwrap() error {
+	return e.Err
+}
+
+// 使用 JSON Pointer 获取数据
+// get data with JSON Pointer and can set default value
+func (this *Array) GetPointer(pointer string, defVal ...any) any {
+	arr, err := this.JSONPointer(pointer)
+	if err == nil && arr.Value() != nil {
+		return arr.Value()
+	}
+
+	if len(defVal) > 0 {
+		return defVal[0]
+	}
+
+	return nil
+}
+
+// 使用 JSON Pointer 获取数据
+// get data with JSON Pointer from source and can set default value
+func GetPointer(source any, pointer string, defVal ...any) any {
+	return New(source).GetPointer(pointer, defVal...)
+}
+
+// 使用 JSON Pointer 判断是否存在，值为 nil 时也存在
+// if JSON Pointer in source return true or false, an explicit nil
+// value exists
+func (this *Array) ExistsPointer(pointer string) bool {
+	arr, err := this.JSONPointer(pointer)
+	if err != nil {
+		return false
+	}
+
+	return arr.Found()
+}
+
+// 使用 JSON Pointer 判断是否存在
+// if JSON Pointer in source return true or false
+func ExistsPointer(source any, pointer string) bool {
+	return New(source).ExistsPointer(pointer)
+}
+
+// 使用 JSON Pointer 设置数据，`-` 为追加
+// set data with JSON Pointer, `-` appends to the array
+func (this *Array) SetPointer(value any, pointer string) (*Array, error) {
+	path, err := JSONPointerToSlice(pointer)
+	if err != nil {
+		return nil, &PointerError{"set", pointer, pointer, err}
+	}
+
+	res, err := this.Set(value, formatPath(path)...)
+	if err != nil {
+		return nil, &PointerError{"set", pointer, this.pointerFailedPrefix(path), err}
+	}
+
+	return res, nil
+}
+
+// 使用 JSON Pointer 删除数据
+// delete data with JSON Pointer
+func (this *Array) DeletePointer(pointer string) error {
+	path, err := JSONPointerToSlice(pointer)
+	if err != nil {
+		return &PointerError{"delete", pointer, pointer, err}
+	}
+
+	if err := this.Delete(formatPath(path)...); err != nil {
+		return &PointerError{"delete", pointer, this.pointerFailedPrefix(path), err}
+	}
+
+	return nil
+}
+
+// 获取失败的 JSON Pointer 前缀
+// returns the shortest pointer prefix that can not be resolved
+func (this *Array) pointerFailedPrefix(path []string) string {
+	for i := 1; i < len(path); i++ {
+		if !this.Search(path[:i]...).Found() {
+			return SliceToJSONPointer(path[:i])
+		}
+	}
+
+	return SliceToJSONPointer(path)
+}
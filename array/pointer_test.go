@@ -0,0 +1,128 @@
+package array
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_GetPointer(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr, _ := ParseJSON([]byte(`{"foo":[{"bar":"1"},{"bar":"2"}],"a/b":{"m~n":8}}`))
+
+	assert(arr.GetPointer("/foo/1/bar"), "2", "GetPointer")
+	assert(arr.GetPointer("/a~1b/m~0n"), float64(8), "GetPointer escaped")
+	assert(arr.GetPointer("/foo/5", "def"), "def", "GetPointer default")
+	assert(arr.GetPointer("foo", "def"), "def", "GetPointer invalid")
+	assert(GetPointer(arrData, "/b/hhTy3/333/qq2"), "qq2ddddd", "GetPointer typed map")
+
+	assert(arr.ExistsPointer("/foo/0/bar"), true, "ExistsPointer")
+	assert(arr.ExistsPointer("/foo/0/baz"), false, "ExistsPointer not exists")
+	assert(ExistsPointer(arrData, "/b/ddd/2"), true, "ExistsPointer typed slice")
+	assert(ExistsPointer(map[string]any{"a": nil}, "/a"), true, "ExistsPointer null")
+	assert(ExistsPointer(map[string]any{"a": nil}, "/b"), false, "ExistsPointer null not exists")
+	assert(arr.ExistsPointer("foo"), false, "ExistsPointer invalid")
+}
+
+func Test_SetPointer(t *testing.T) {
+	assert := assertT(t)
+
+	arr := New(nil)
+
+	if _, err := arr.SetPointer([]any{}, "/foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetPointer(1, "/foo/-"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetPointer(2, "/foo/-"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetPointer(3, "/foo/0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetPointer("v", "/a~1b/m~0n"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr.String(), `{"a/b":{"m~n":"v"},"foo":[3,2]}`, "SetPointer")
+
+	typed := New(map[string]any{
+		"list": []int64{1, 2},
+		"ptr":  &[]float64{1.5},
+		"m":    map[string]int{"a": 1},
+	})
+
+	if _, err := typed.SetPointer(int64(3), "/list/-"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := typed.SetPointer(2.5, "/ptr/-"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := typed.SetPointer(5, "/m/b"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(typed.String(), `{"list":[1,2,3],"m":{"a":1,"b":5},"ptr":[1.5,2.5]}`, "SetPointer typed")
+
+	root := New([]any{1})
+	if _, err := root.SetPointer(2, "/-"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(root.String(), `[1,2]`, "SetPointer root append")
+
+	_, err := arr.SetPointer(1, "/foo/9/bar")
+
+	var pointerErr *PointerError
+	if !errors.As(err, &pointerErr) {
+		t.Fatalf("SetPointer should return PointerError, got %v", err)
+	}
+
+	assert(pointerErr.Prefix, "/foo/9", "SetPointer error prefix")
+
+	if _, err := arr.SetPointer(1, "foo"); err == nil {
+		t.Error("SetPointer should error on invalid pointer")
+	}
+}
+
+func Test_DeletePointer(t *testing.T) {
+	assert := assertT(t)
+
+	arr, _ := ParseJSON([]byte(`{"foo":[{"bar":"1"},{"bar":"2"}],"baz":{"q":1}}`))
+
+	if err := arr.DeletePointer("/foo/0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := arr.DeletePointer("/baz/q"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr.String(), `{"baz":{},"foo":[{"bar":"2"}]}`, "DeletePointer")
+
+	err := arr.DeletePointer("/nothing/q")
+
+	var pointerErr *PointerError
+	if !errors.As(err, &pointerErr) {
+		t.Fatalf("DeletePointer should return PointerError, got %v", err)
+	}
+
+	assert(pointerErr.Prefix, "/nothing", "DeletePointer error prefix")
+
+	typed := New(map[string]any{"list": []int64{1, 2, 3}})
+	if err := typed.DeletePointer("/list/1"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(typed.String(), `{"list":[1,3]}`, "DeletePointer typed")
+}
+
+func Test_SliceToJSONPointer(t *testing.T) {
+	assert := assertT(t)
+
+	pointer := SliceToJSONPointer([]string{"a/b", "m~n", "0"})
+	assert(pointer, "/a~1b/m~0n/0", "SliceToJSONPointer")
+
+	path, _ := JSONPointerToSlice(pointer)
+	assert(SliceToJSONPointer(path), pointer, "SliceToJSONPointer round trip")
+}
@@ -0,0 +1,1158 @@
+package array
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSONPath 语法错误
+// JSONPathSyntaxError reports a JSONPath syntax error and the column
+// (1-based, in bytes) where it was found.
+type JSONPathSyntaxError struct {
+	Expr   string
+	Column int
+	Msg    string
+}
+
+func (e *JSONPathSyntaxError) Error() string {
+	return fmt.Sprintf("jsonpath: %s at column %d", e.Msg, e.Column)
+}
+
+// JSONPath 查询表达式
+// JSONPath is a parsed JSONPath (RFC 9535) expression
+type JSONPath struct {
+	expr     string
+	segments []querySegment
+}
+
+// 解析 JSONPath
+// parse JSONPath expression
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	p := &jsonPathParser{expr: expr}
+
+	segments, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONPath{
+		expr:     expr,
+		segments: segments,
+	}, nil
+}
+
+// 返回表达式
+// return the expression string
+func (this *JSONPath) String() string {
+	return this.expr
+}
+
+// JSONPath 查询
+// Query returns all of the values matched by the JSONPath expression
+func (this *Array) Query(expr string) ([]*Array, error) {
+	path, err := ParseJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.QueryJSONPath(path), nil
+}
+
+// JSONPath 查询
+// Query returns all of the values from source matched by the JSONPath expression
+func Query(source any, expr string) ([]*Array, error) {
+	return New(source).Query(expr)
+}
+
+// 使用已解析的 JSONPath 查询
+// QueryJSONPath returns all of the values matched by the parsed JSONPath
+func (this *Array) QueryJSONPath(path *JSONPath) []*Array {
+	nodes := this.queryNodes(this.source, this.source, path.segments)
+
+	res := make([]*Array, 0, len(nodes))
+	for _, node := range nodes {
+		res = append(res, this.child(node.value, append([]string{}, node.path...)))
+	}
+
+	return res
+}
+
+const (
+	selectorName = iota
+	selectorWildcard
+	selectorIndex
+	selectorSlice
+	selectorFilter
+)
+
+type querySegment struct {
+	descendant bool
+	selectors  []querySelector
+}
+
+type querySelector struct {
+	kind   int
+	name   string
+	index  int
+	slice  [3]*int
+	filter queryExpr
+}
+
+type queryNode struct {
+	value any
+	path  []string
+}
+
+// 查询节点
+// queryNodes applies segments on the value
+func (this *Array) queryNodes(root, value any, segments []querySegment) []queryNode {
+	nodes := []queryNode{{value: value}}
+
+	for _, segment := range segments {
+		next := make([]queryNode, 0)
+
+		for _, node := range nodes {
+			if segment.descendant {
+				for _, desc := range this.queryDescendants(node) {
+					next = append(next, this.querySelect(root, desc, segment.selectors)...)
+				}
+			} else {
+				next = append(next, this.querySelect(root, node, segment.selectors)...)
+			}
+		}
+
+		nodes = next
+	}
+
+	return nodes
+}
+
+func (this *Array) querySelect(root any, node queryNode, selectors []querySelector) []queryNode {
+	res := make([]queryNode, 0)
+
+	for _, sel := range selectors {
+		switch sel.kind {
+		case selectorName:
+			if m, ok := this.anyDataFormat(node.value).(map[string]any); ok {
+				if v, ok := m[sel.name]; ok {
+					res = append(res, node.child(sel.name, v))
+				}
+			}
+		case selectorWildcard:
+			res = append(res, this.queryChildren(node)...)
+		case selectorIndex:
+			if s, ok := this.anyDataFormat(node.value).([]any); ok {
+				index := sel.index
+				if index < 0 {
+					index += len(s)
+				}
+
+				if index >= 0 && index < len(s) {
+					res = append(res, node.child(strconv.Itoa(index), s[index]))
+				}
+			}
+		case selectorSlice:
+			if s, ok := this.anyDataFormat(node.value).([]any); ok {
+				for _, index := range sliceIndexes(sel.slice, len(s)) {
+					res = append(res, node.child(strconv.Itoa(index), s[index]))
+				}
+			}
+		case selectorFilter:
+			for _, child := range this.queryChildren(node) {
+				ctx := &queryContext{arr: this, root: root, current: child.value}
+				if sel.filter.test(ctx) {
+					res = append(res, child)
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// 子节点，map 按 key 排序
+// children of the node, map keys are sorted
+func (this *Array) queryChildren(node queryNode) []queryNode {
+	res := make([]queryNode, 0)
+
+	switch n := this.anyDataFormat(node.value).(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			res = append(res, node.child(k, n[k]))
+		}
+	case []any:
+		for i, v := range n {
+			res = append(res, node.child(strconv.Itoa(i), v))
+		}
+	}
+
+	return res
+}
+
+// 后代节点，包括节点自身，跳过循环引用的节点
+// descendants of the node and the node itself, nodes of cycles are skipped
+func (this *Array) queryDescendants(node queryNode) []queryNode {
+	return this.queryDescendantsSeen(node, cycleDetector{})
+}
+
+func (this *Array) queryDescendantsSeen(node queryNode, seen cycleDetector) []queryNode {
+	value := reflect.ValueOf(node.value)
+	if !seen.enter(value) {
+		return nil
+	}
+	defer seen.leave(value)
+
+	res := []queryNode{node}
+	for _, child := range this.queryChildren(node) {
+		res = append(res, this.queryDescendantsSeen(child, seen)...)
+	}
+
+	return res
+}
+
+func (this queryNode) child(key string, value any) queryNode {
+	return queryNode{
+		value: value,
+		path:  appendPath(this.path, key),
+	}
+}
+
+// 切片索引
+// slice indexes with start, end and step
+func sliceIndexes(slice [3]*int, length int) []int {
+	step := 1
+	if slice[2] != nil {
+		step = *slice[2]
+	}
+
+	if step == 0 {
+		return nil
+	}
+
+	normalize := func(i int) int {
+		if i >= 0 {
+			return i
+		}
+
+		return length + i
+	}
+
+	clamp := func(i, lower, upper int) int {
+		if i < lower {
+			return lower
+		}
+		if i > upper {
+			return upper
+		}
+
+		return i
+	}
+
+	indexes := make([]int, 0)
+
+	if step > 0 {
+		start, end := 0, length
+		if slice[0] != nil {
+			start = normalize(*slice[0])
+		}
+		if slice[1] != nil {
+			end = normalize(*slice[1])
+		}
+
+		lower, upper := clamp(start, 0, length), clamp(end, 0, length)
+		for i := lower; i < upper; i += step {
+			indexes = append(indexes, i)
+		}
+	} else {
+		start, end := length-1, -length-1
+		if slice[0] != nil {
+			start = normalize(*slice[0])
+		}
+		if slice[1] != nil {
+			end = normalize(*slice[1])
+		}
+
+		upper, lower := clamp(start, -1, length-1), clamp(end, -1, length-1)
+		for i := upper; lower < i; i += step {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+// ===== filter =====
+
+type queryContext struct {
+	arr     *Array
+	root    any
+	current any
+}
+
+type queryExpr interface {
+	test(ctx *queryContext) bool
+}
+
+type queryOperand interface {
+	eval(ctx *queryContext) (any, bool)
+}
+
+type queryOr struct {
+	left, right queryExpr
+}
+
+func (this *queryOr) test(ctx *queryContext) bool {
+	return this.left.test(ctx) || this.right.test(ctx)
+}
+
+type queryAnd struct {
+	left, right queryExpr
+}
+
+func (this *queryAnd) test(ctx *queryContext) bool {
+	return this.left.test(ctx) && this.right.test(ctx)
+}
+
+type queryNot struct {
+	expr queryExpr
+}
+
+func (this *queryNot) test(ctx *queryContext) bool {
+	return !this.expr.test(ctx)
+}
+
+type queryCompare struct {
+	op          string
+	left, right queryOperand
+}
+
+func (this *queryCompare) test(ctx *queryContext) bool {
+	a, aok := this.left.eval(ctx)
+	b, bok := this.right.eval(ctx)
+
+	return ctx.arr.queryCompareValues(this.op, a, aok, b, bok)
+}
+
+type queryLiteral struct {
+	value any
+}
+
+func (this *queryLiteral) eval(ctx *queryContext) (any, bool) {
+	return this.value, true
+}
+
+type queryPath struct {
+	relative bool
+	segments []querySegment
+}
+
+func (this *queryPath) nodes(ctx *queryContext) []queryNode {
+	value := ctx.root
+	if this.relative {
+		value = ctx.current
+	}
+
+	return ctx.arr.queryNodes(ctx.root, value, this.segments)
+}
+
+func (this *queryPath) eval(ctx *queryContext) (any, bool) {
+	nodes := this.nodes(ctx)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+
+	return nodes[0].value, true
+}
+
+// 存在判断
+// existence test
+func (this *queryPath) test(ctx *queryContext) bool {
+	return len(this.nodes(ctx)) > 0
+}
+
+type queryFunc struct {
+	name string
+	args []queryOperand
+}
+
+func (this *queryFunc) eval(ctx *queryContext) (any, bool) {
+	switch this.name {
+	case "length":
+		v, ok := this.args[0].eval(ctx)
+		if !ok {
+			return nil, false
+		}
+
+		if s, ok := v.(string); ok {
+			return utf8.RuneCountInString(s), true
+		}
+
+		switch n := ctx.arr.anyDataFormat(v).(type) {
+		case map[string]any:
+			return len(n), true
+		case []any:
+			return len(n), true
+		}
+
+		return nil, false
+	case "count":
+		if path, ok := this.args[0].(*queryPath); ok {
+			return len(path.nodes(ctx)), true
+		}
+
+		return nil, false
+	case "value":
+		return this.args[0].eval(ctx)
+	case "match", "search":
+		return this.test(ctx), true
+	}
+
+	return nil, false
+}
+
+func (this *queryFunc) test(ctx *queryContext) bool {
+	switch this.name {
+	case "match", "search":
+		v, ok := this.args[0].eval(ctx)
+		if !ok {
+			return false
+		}
+		r, ok := this.args[1].eval(ctx)
+		if !ok {
+			return false
+		}
+
+		str, ok := v.(string)
+		if !ok {
+			return false
+		}
+		pattern, ok := r.(string)
+		if !ok {
+			return false
+		}
+
+		if this.name == "match" {
+			pattern = "^(?:" + pattern + ")$"
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false
+		}
+
+		return re.MatchString(str)
+	}
+
+	_, ok := this.eval(ctx)
+	return ok
+}
+
+var queryFuncArgs = map[string]int{
+	"length": 1,
+	"count":  1,
+	"value":  1,
+	"match":  2,
+	"search": 2,
+}
+
+// 比较
+// compare values, not found values are only equal to each other
+func (this *Array) queryCompareValues(op string, a any, aok bool, b any, bok bool) bool {
+	switch op {
+	case "==":
+		if !aok || !bok {
+			return !aok && !bok
+		}
+
+		return this.queryEqual(a, b)
+	case "!=":
+		return !this.queryCompareValues("==", a, aok, b, bok)
+	case "<":
+		return aok && bok && queryLess(a, b)
+	case ">":
+		return aok && bok && queryLess(b, a)
+	case "<=":
+		return aok && bok && (queryLess(a, b) || this.queryEqual(a, b))
+	case ">=":
+		return aok && bok && (queryLess(b, a) || this.queryEqual(a, b))
+	}
+
+	return false
+}
+
+func (this *Array) queryEqual(a, b any) bool {
+	return this.queryEqualSeen(a, b, cycleDetector{}, cycleDetector{})
+}
+
+func (this *Array) queryEqualSeen(a, b any, seenA, seenB cycleDetector) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if an, ok := toQueryNumber(a); ok {
+		bn, ok := toQueryNumber(b)
+		return ok && an == bn
+	}
+
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	}
+
+	// 循环引用使用 reflect.DeepEqual 比较
+	// cycles are compared with reflect.DeepEqual
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !seenA.enter(va) {
+		return reflect.DeepEqual(a, b)
+	}
+	defer seenA.leave(va)
+
+	if !seenB.enter(vb) {
+		return reflect.DeepEqual(a, b)
+	}
+	defer seenB.leave(vb)
+
+	switch av := this.anyDataFormat(a).(type) {
+	case map[string]any:
+		bv, ok := this.anyDataFormat(b).(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for k, v := range av {
+			w, ok := bv[k]
+			if !ok || !this.queryEqualSeen(v, w, seenA, seenB) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		bv, ok := this.anyDataFormat(b).([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for k := range av {
+			if !this.queryEqualSeen(av[k], bv[k], seenA, seenB) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func queryLess(a, b any) bool {
+	if an, ok := toQueryNumber(a); ok {
+		bn, ok := toQueryNumber(b)
+		return ok && an < bn
+	}
+
+	as, ok := a.(string)
+	if !ok {
+		return false
+	}
+
+	bs, ok := b.(string)
+	return ok && as < bs
+}
+
+func toQueryNumber(v any) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+
+	return 0, false
+}
+
+// ===== parser =====
+
+type jsonPathParser struct {
+	expr string
+	pos  int
+}
+
+func (p *jsonPathParser) errorf(format string, args ...any) error {
+	return &JSONPathSyntaxError{
+		Expr:   p.expr,
+		Column: p.pos + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *jsonPathParser) eof() bool {
+	return p.pos >= len(p.expr)
+}
+
+func (p *jsonPathParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.expr[p.pos]
+}
+
+func (p *jsonPathParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.expr[p.pos:], s)
+}
+
+func (p *jsonPathParser) skipSpace() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *jsonPathParser) expect(c byte) error {
+	if p.peek() != c {
+		if p.eof() {
+			return p.errorf("expected '%c' but found end of expression", c)
+		}
+
+		return p.errorf("expected '%c' but found '%c'", c, p.peek())
+	}
+
+	p.pos++
+	return nil
+}
+
+func (p *jsonPathParser) parse() ([]querySegment, error) {
+	if err := p.expect('$'); err != nil {
+		return nil, err
+	}
+
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected character '%c'", p.peek())
+	}
+
+	return segments, nil
+}
+
+func (p *jsonPathParser) parseSegments() ([]querySegment, error) {
+	segments := make([]querySegment, 0)
+
+	for {
+		start := p.pos
+
+		p.skipSpace()
+		if p.peek() != '.' && p.peek() != '[' {
+			p.pos = start
+			return segments, nil
+		}
+
+		segment, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, segment)
+	}
+}
+
+func (p *jsonPathParser) parseSegment() (querySegment, error) {
+	if p.peek() == '[' {
+		selectors, err := p.parseBracket()
+		return querySegment{selectors: selectors}, err
+	}
+
+	p.pos++
+
+	descendant := false
+	if p.peek() == '.' {
+		descendant = true
+		p.pos++
+
+		if p.peek() == '[' {
+			selectors, err := p.parseBracket()
+			return querySegment{descendant: true, selectors: selectors}, err
+		}
+	}
+
+	if p.peek() == '*' {
+		p.pos++
+		return querySegment{
+			descendant: descendant,
+			selectors:  []querySelector{{kind: selectorWildcard}},
+		}, nil
+	}
+
+	name, ok := p.parseMemberName()
+	if !ok {
+		if p.eof() {
+			return querySegment{}, p.errorf("expected member name but found end of expression")
+		}
+
+		return querySegment{}, p.errorf("invalid member name character '%c'", p.peek())
+	}
+
+	return querySegment{
+		descendant: descendant,
+		selectors:  []querySelector{{kind: selectorName, name: name}},
+	}, nil
+}
+
+func isNameFirst(r rune) bool {
+	return r == '_' || r >= 0x80 || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func (p *jsonPathParser) parseMemberName() (string, bool) {
+	start := p.pos
+
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		if !isNameFirst(r) && !(p.pos > start && r >= '0' && r <= '9') {
+			break
+		}
+
+		p.pos += size
+	}
+
+	return p.expr[start:p.pos], p.pos > start
+}
+
+func (p *jsonPathParser) parseBracket() ([]querySelector, error) {
+	p.pos++
+
+	selectors := make([]querySelector, 0)
+	for {
+		p.skipSpace()
+
+		selector, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+
+		selectors = append(selectors, selector)
+
+		p.skipSpace()
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+
+		if err := p.expect(']'); err != nil {
+			return nil, err
+		}
+
+		return selectors, nil
+	}
+}
+
+func (p *jsonPathParser) parseSelector() (querySelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		return querySelector{kind: selectorName, name: name}, err
+	case c == '*':
+		p.pos++
+		return querySelector{kind: selectorWildcard}, nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+
+		filter, err := p.parseLogicalOr()
+		return querySelector{kind: selectorFilter, filter: filter}, err
+	case c == '-' || c == ':' || (c >= '0' && c <= '9'):
+		return p.parseIndexOrSlice()
+	case p.eof():
+		return querySelector{}, p.errorf("expected selector but found end of expression")
+	}
+
+	return querySelector{}, p.errorf("invalid selector character '%c'", p.peek())
+}
+
+func (p *jsonPathParser) parseIndexOrSlice() (querySelector, error) {
+	var slice [3]*int
+
+	for i := 0; i < 3; i++ {
+		p.skipSpace()
+
+		if c := p.peek(); c == '-' || (c >= '0' && c <= '9') {
+			n, err := p.parseInt()
+			if err != nil {
+				return querySelector{}, err
+			}
+
+			slice[i] = &n
+		}
+
+		p.skipSpace()
+
+		if i == 0 && p.peek() != ':' {
+			if slice[0] == nil {
+				return querySelector{}, p.errorf("expected index")
+			}
+
+			return querySelector{kind: selectorIndex, index: *slice[0]}, nil
+		}
+
+		if i == 2 || p.peek() != ':' {
+			break
+		}
+
+		p.pos++
+	}
+
+	return querySelector{kind: selectorSlice, slice: slice}, nil
+}
+
+func (p *jsonPathParser) parseInt() (int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	digits := p.pos
+	for c := p.peek(); c >= '0' && c <= '9'; c = p.peek() {
+		p.pos++
+	}
+
+	// RFC 9535 不允许前导零和 -0
+	// RFC 9535 does not allow leading zeros and -0
+	if p.expr[digits:p.pos] == "0" && digits > start ||
+		p.pos-digits > 1 && p.expr[digits] == '0' {
+		p.pos = start
+		return 0, p.errorf("invalid integer with leading zero")
+	}
+
+	n, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid integer")
+	}
+
+	return n, nil
+}
+
+func (p *jsonPathParser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.peek()
+		if c == quote {
+			p.pos++
+			return sb.String(), nil
+		}
+
+		if c != '\\' {
+			r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+			continue
+		}
+
+		p.pos++
+		switch e := p.peek(); e {
+		case '\'', '"', '\\', '/':
+			sb.WriteByte(e)
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'u':
+			if p.pos+5 > len(p.expr) {
+				return "", p.errorf("invalid unicode escape")
+			}
+
+			n, err := strconv.ParseUint(p.expr[p.pos+1:p.pos+5], 16, 32)
+			if err != nil {
+				return "", p.errorf("invalid unicode escape")
+			}
+
+			sb.WriteRune(rune(n))
+			p.pos += 4
+		default:
+			return "", p.errorf("invalid escape character")
+		}
+
+		p.pos++
+	}
+}
+
+func (p *jsonPathParser) parseLogicalOr() (queryExpr, error) {
+	left, err := p.parseLogicalAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpace()
+		if !p.hasPrefix("||") {
+			return left, nil
+		}
+
+		p.pos += 2
+		p.skipSpace()
+
+		right, err := p.parseLogicalAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &queryOr{left, right}
+	}
+}
+
+func (p *jsonPathParser) parseLogicalAnd() (queryExpr, error) {
+	left, err := p.parseBasicExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpace()
+		if !p.hasPrefix("&&") {
+			return left, nil
+		}
+
+		p.pos += 2
+		p.skipSpace()
+
+		right, err := p.parseBasicExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &queryAnd{left, right}
+	}
+}
+
+func (p *jsonPathParser) parseBasicExpr() (queryExpr, error) {
+	if p.peek() == '!' && !p.hasPrefix("!=") {
+		p.pos++
+		p.skipSpace()
+
+		expr, err := p.parseBasicExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		return &queryNot{expr}, nil
+	}
+
+	if p.peek() == '(' {
+		p.pos++
+		p.skipSpace()
+
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+
+		return expr, nil
+	}
+
+	start := p.pos
+
+	left, err := p.parseComparable()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.hasPrefix(op) {
+			p.pos += len(op)
+			p.skipSpace()
+
+			right, err := p.parseComparable()
+			if err != nil {
+				return nil, err
+			}
+
+			return &queryCompare{op: op, left: left, right: right}, nil
+		}
+	}
+
+	if expr, ok := left.(queryExpr); ok {
+		return expr, nil
+	}
+
+	p.pos = start
+	return nil, p.errorf("literal must be compared")
+}
+
+func (p *jsonPathParser) parseComparable() (queryOperand, error) {
+	c := p.peek()
+
+	switch {
+	case c == '@' || c == '$':
+		p.pos++
+
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+
+		return &queryPath{relative: c == '@', segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+
+		return &queryLiteral{s}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case c >= 'a' && c <= 'z':
+		start := p.pos
+		for c := p.peek(); (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_'; c = p.peek() {
+			p.pos++
+		}
+
+		name := p.expr[start:p.pos]
+		if p.peek() != '(' {
+			switch name {
+			case "true":
+				return &queryLiteral{true}, nil
+			case "false":
+				return &queryLiteral{false}, nil
+			case "null":
+				return &queryLiteral{nil}, nil
+			}
+
+			p.pos = start
+			return nil, p.errorf("unknown literal '%s'", name)
+		}
+
+		return p.parseFunc(start, name)
+	case p.eof():
+		return nil, p.errorf("expected expression but found end of expression")
+	}
+
+	return nil, p.errorf("unexpected character '%c'", c)
+}
+
+func (p *jsonPathParser) parseFunc(start int, name string) (queryOperand, error) {
+	argc, ok := queryFuncArgs[name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function '%s'", name)
+	}
+
+	p.pos++
+
+	args := make([]queryOperand, 0)
+	for {
+		p.skipSpace()
+		if p.peek() == ')' && len(args) == 0 {
+			break
+		}
+
+		arg, err := p.parseComparable()
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+
+		p.skipSpace()
+		if p.peek() != ',' {
+			break
+		}
+
+		p.pos++
+	}
+
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+
+	if len(args) != argc {
+		p.pos = start
+		return nil, p.errorf("function '%s' expects %d arguments", name, argc)
+	}
+
+	if name == "count" {
+		if _, ok := args[0].(*queryPath); !ok {
+			p.pos = start
+			return nil, p.errorf("function '%s' expects a query argument", name)
+		}
+	}
+
+	return &queryFunc{name: name, args: args}, nil
+}
+
+func (p *jsonPathParser) parseNumber() (queryOperand, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	for !p.eof() {
+		c := p.peek()
+		if (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' ||
+			((c == '+' || c == '-') && (p.expr[p.pos-1] == 'e' || p.expr[p.pos-1] == 'E')) {
+			p.pos++
+			continue
+		}
+
+		break
+	}
+
+	n, err := strconv.ParseFloat(p.expr[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+
+	return &queryLiteral{n}, nil
+}
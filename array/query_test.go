@@ -0,0 +1,156 @@
+package array
+
+import (
+	"errors"
+	"testing"
+)
+
+var queryJSONData = []byte(`{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	}
+}`)
+
+func queryValues(res []*Array) []any {
+	values := make([]any, 0, len(res))
+	for _, v := range res {
+		values = append(values, v.Value())
+	}
+
+	return values
+}
+
+func Test_Query(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr, err := ParseJSON(queryJSONData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		expr  string
+		check []any
+	}{
+		{"$.store.book[*].author", []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{"$..author", []any{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{"$.store..price", []any{float64(399), 8.95, 12.99, 8.99, 22.99}},
+		{"$..book[2].title", []any{"Moby Dick"}},
+		{"$..book[-1].title", []any{"The Lord of the Rings"}},
+		{"$..book[0,1].title", []any{"Sayings of the Century", "Sword of Honour"}},
+		{"$..book[:2].title", []any{"Sayings of the Century", "Sword of Honour"}},
+		{"$..book[1:4:2].title", []any{"Sword of Honour", "The Lord of the Rings"}},
+		{"$..book[::-1].price", []any{22.99, 8.99, 12.99, 8.95}},
+		{"$['store']['bicycle']['color']", []any{"red"}},
+		{`$["store"].bicycle["color", 'price']`, []any{"red", float64(399)}},
+		{"$..book[?(@.isbn)].title", []any{"Moby Dick", "The Lord of the Rings"}},
+		{"$..book[?@.price < 10].title", []any{"Sayings of the Century", "Moby Dick"}},
+		{"$..book[?(@.price < 10 && @.category == 'fiction')].title", []any{"Moby Dick"}},
+		{"$..book[?(@.price > 20 || @.author == 'Nigel Rees')].title", []any{"Sayings of the Century", "The Lord of the Rings"}},
+		{"$..book[?(!@.isbn)].title", []any{"Sayings of the Century", "Sword of Honour"}},
+		{"$..book[?(@.price <= $.store.book[0].price)].title", []any{"Sayings of the Century"}},
+		{"$..book[?match(@.author, 'J.*')].title", []any{"The Lord of the Rings"}},
+		{"$..book[?search(@.title, 'of')].title", []any{"Sayings of the Century", "Sword of Honour", "The Lord of the Rings"}},
+		{"$..book[?length(@.title) == 9].title", []any{"Moby Dick"}},
+		{"$.store[?count(@.*) == 2].color", []any{"red"}},
+		{"$.store.bicycle.nothing", []any{}},
+		{"$.store.book[10]", []any{}},
+	}
+
+	for _, v := range testData {
+		t.Run(v.expr, func(t *testing.T) {
+			res, err := arr.Query(v.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert(queryValues(res), v.check, "Query "+v.expr)
+		})
+	}
+}
+
+func Test_QueryTypedSource(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	res, err := Query(arrData, "$.b.hhTy3[?(@ == 'hccccc')]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(queryValues(res), []any{"hccccc"}, "Query typed map")
+
+	res, err = Query(arrData, "$.b.qqq[1:3]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(queryValues(res), []any{int64(333), int64(555)}, "Query fixed array")
+
+	res, err = Query(arrData, "$.b.hhTy66['777'][?(@ > 30)]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(queryValues(res), []any{32.5, 789.156}, "Query pointer slice")
+
+	res, err = Query(map[string]any{
+		"ff": map[any]any{
+			111: "fccccc",
+			222: "fddddd",
+			333: "dfffff",
+		},
+	}, "$.ff.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(queryValues(res), []any{"fccccc", "fddddd", "dfffff"}, "Query map[any]any")
+}
+
+func Test_QueryCycle(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	res, err := Query(newTestStructCycle(), "$..name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(queryValues(res), []any{"n"}, "Query descendants of cycle")
+}
+
+func Test_QuerySyntaxError(t *testing.T) {
+	testData := []struct {
+		expr   string
+		column int
+	}{
+		{"store", 1},
+		{"$.", 3},
+		{"$.store[", 9},
+		{"$.store[0", 10},
+		{"$.store['book", 14},
+		{"$.store[?(@.price < )]", 21},
+		{"$.store[?(@.price == 1]", 23},
+		{"$.store[?foo(@)]", 10},
+		{"$.store[?(1)]", 11},
+		{"$.store x", 9},
+		{"$.store[01]", 9},
+		{"$.store[-0]", 9},
+		{"$.store[1:-01]", 11},
+	}
+
+	for _, v := range testData {
+		t.Run(v.expr, func(t *testing.T) {
+			_, err := Query(nil, v.expr)
+
+			var syntaxErr *JSONPathSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Query should return JSONPathSyntaxError, got %v", err)
+			}
+
+			if syntaxErr.Column != v.column {
+				t.Errorf("Query error column got %d, want %d (%v)", syntaxErr.Column, v.column, err)
+			}
+		})
+	}
+}
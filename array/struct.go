@@ -0,0 +1,299 @@
+package array
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 默认结构体标签
+// default struct tag name
+const defaultTagName = "json"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// 结构体字段
+// struct field info
+type structField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+}
+
+type structFieldsKey struct {
+	typ     reflect.Type
+	tagName string
+	squash  bool
+}
+
+// 字段缓存
+// struct fields cache
+var structFieldsCache sync.Map
+
+// 获取结构体字段
+// get struct fields with tag name, embedded fields are promoted
+func getStructFields(typ reflect.Type, tagName string) []structField {
+	return cachedStructFields(typ, tagName, false)
+}
+
+// 获取 Decode 使用的结构体字段，有 squash 标签的结构体字段也展开
+// get struct fields for Decode, struct fields tagged with squash are
+// promoted too
+func getDecodeStructFields(typ reflect.Type, tagName string) []structField {
+	return cachedStructFields(typ, tagName, true)
+}
+
+func cachedStructFields(typ reflect.Type, tagName string, squash bool) []structField {
+	key := structFieldsKey{typ, tagName, squash}
+	if f, ok := structFieldsCache.Load(key); ok {
+		return f.([]structField)
+	}
+
+	fields := dominantStructFields(collectStructFields(typ, tagName, squash, nil, map[reflect.Type]bool{}))
+
+	f, _ := structFieldsCache.LoadOrStore(key, fields)
+	return f.([]structField)
+}
+
+func collectStructFields(typ reflect.Type, tagName string, squashable bool, index []int, visited map[reflect.Type]bool) []structField {
+	if visited[typ] {
+		return nil
+	}
+
+	visited[typ] = true
+	defer delete(visited, typ)
+
+	fields := make([]structField, 0)
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+
+		tag := sf.Tag.Get(tagName)
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		// 嵌入的结构体展开，squashable 时有 squash 标签的结构体字段也展开
+		// embedded structs are promoted, and struct fields tagged with squash
+		// too when squashable is true
+		squash := squashable && ft.Kind() == reflect.Struct && hasTagOption(opts, "squash")
+
+		if sf.Anonymous {
+			if (name == "" || squash) && ft.Kind() == reflect.Struct {
+				fields = append(fields, collectStructFields(ft, tagName, squashable, fieldIndex, visited)...)
+				continue
+			}
+
+			if !sf.IsExported() && ft.Kind() != reflect.Struct {
+				continue
+			}
+		} else if !sf.IsExported() {
+			continue
+		} else if squash {
+			fields = append(fields, collectStructFields(ft, tagName, squashable, fieldIndex, visited)...)
+			continue
+		}
+
+		tagged := name != ""
+		if !tagged {
+			name = sf.Name
+		}
+
+		fields = append(fields, structField{
+			name:      name,
+			index:     fieldIndex,
+			tagged:    tagged,
+			omitEmpty: hasTagOption(opts, "omitempty"),
+		})
+	}
+
+	return fields
+}
+
+// 同名字段以层级浅的为准，同层级时以有标签的为准
+// keep the dominant field for every name, like encoding/json
+func dominantStructFields(fields []structField) []structField {
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].name != fields[j].name {
+			return fields[i].name < fields[j].name
+		}
+		if len(fields[i].index) != len(fields[j].index) {
+			return len(fields[i].index) < len(fields[j].index)
+		}
+
+		return fields[i].tagged && !fields[j].tagged
+	})
+
+	out := make([]structField, 0, len(fields))
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+
+		group := fields[i:j]
+		if len(group) == 1 ||
+			len(group[0].index) < len(group[1].index) ||
+			(group[0].tagged && !group[1].tagged) {
+			out = append(out, group[0])
+		}
+
+		i = j
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return lessIndex(out[i].index, out[j].index)
+	})
+
+	return out
+}
+
+func lessIndex(a, b []int) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var name string
+		name, opts, _ = strings.Cut(opts, ",")
+		if name == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 查找结构体字段
+// find struct field by name
+func findStructField(typ reflect.Type, tagName string, name string) (structField, bool) {
+	for _, f := range getStructFields(typ, tagName) {
+		if f.name == name {
+			return f, true
+		}
+	}
+
+	return structField{}, false
+}
+
+// 获取字段值，嵌入的空指针在 alloc 时创建，否则返回 false
+// get field value, a nil embedded pointer is allocated when alloc
+// is true, or returns false
+func structFieldValue(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// 结构体转为 map，omitEmpty 时按照 omitempty 省略空字段
+// struct to map with field names, the empty fields tagged with omitempty
+// are omitted when omitEmpty is true
+func structToMap(v reflect.Value, tagName string, omitEmpty bool) map[any]any {
+	m := make(map[any]any)
+
+	for _, f := range getStructFields(v.Type(), tagName) {
+		fv, ok := structFieldValue(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		if omitEmpty && f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		// 未导出的嵌入结构体不能取值，和 encoding/json 一样使用导出的字段
+		// unexported embedded structs can not be read with Interface, use
+		// their exported fields like encoding/json
+		if !fv.CanInterface() {
+			for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+
+			if isTraversableStruct(fv) {
+				m[f.name] = structToMap(fv, tagName, omitEmpty)
+			}
+
+			continue
+		}
+
+		m[f.name] = fv.Interface()
+	}
+
+	return m
+}
+
+// 判断是否作为结构体遍历，实现了 Marshaler 的作为值使用
+// if the value is a struct that can be traversed
+func isTraversableStruct(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+
+	return !isMarshalerType(v.Type())
+}
+
+func isMarshalerType(typ reflect.Type) bool {
+	if typ.Implements(jsonMarshalerType) || typ.Implements(textMarshalerType) {
+		return true
+	}
+
+	ptr := reflect.PointerTo(typ)
+
+	return ptr.Implements(jsonMarshalerType) || ptr.Implements(textMarshalerType)
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+
+	return false
+}
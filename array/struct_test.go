@@ -0,0 +1,368 @@
+package array
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testStructBase struct {
+	ID      int    `json:"id"`
+	Version string `json:"version,omitempty"`
+}
+
+type testStructServer struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	Tags []string
+}
+
+type testStructConfig struct {
+	testStructBase
+
+	Name     string                       `json:"name"`
+	Server   *testStructServer            `json:"server"`
+	Servers  map[string]testStructServer  `json:"servers"`
+	Backups  []testStructServer           `json:"backups,omitempty"`
+	Secret   string                       `json:"-"`
+	Created  time.Time                    `json:"created"`
+	Extra    map[any]any                  `json:"extra,omitempty"`
+	Nested   *testStructConfig            `json:"nested,omitempty"`
+	Pointers map[string]*testStructServer `json:"pointers,omitempty"`
+	private  string
+}
+
+func newTestStructConfig() *testStructConfig {
+	return &testStructConfig{
+		testStructBase: testStructBase{
+			ID: 12,
+		},
+		Name: "app",
+		Server: &testStructServer{
+			Host: "localhost",
+			Port: 8080,
+			Tags: []string{"a", "b"},
+		},
+		Servers: map[string]testStructServer{
+			"prod": {
+				Host: "prod.host",
+				Port: 443,
+			},
+		},
+		Secret:  "secret",
+		Created: time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC),
+		private: "private",
+	}
+}
+
+func Test_StructGet(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	cfg := newTestStructConfig()
+
+	testData := []struct {
+		key   string
+		check any
+	}{
+		{"name", "app"},
+		{"id", 12},
+		{"server.host", "localhost"},
+		{"server.port", 8080},
+		{"server.Tags.1", "b"},
+		{"servers.prod.host", "prod.host"},
+		{"created", cfg.Created},
+		{"version", ""},
+		{"Secret", nil},
+		{"secret", nil},
+		{"private", nil},
+		{"Name", nil},
+		{"backups", []testStructServer(nil)},
+		{"server.nothing", nil},
+	}
+
+	for _, v := range testData {
+		t.Run(v.key, func(t *testing.T) {
+			assert(Get(cfg, v.key), v.check, "Get "+v.key)
+			assert(Get(*cfg, v.key), v.check, "Get value "+v.key)
+		})
+	}
+
+	mixed := map[string]any{
+		"cfg": cfg,
+		"list": []any{
+			testStructServer{Host: "h1"},
+		},
+	}
+
+	assert(Get(mixed, "cfg.server.port"), 8080, "Get mixed")
+	assert(Get(mixed, "list.0.host"), "h1", "Get mixed list")
+	assert(Exists(mixed, "cfg.servers.prod"), true, "Exists mixed")
+	assert(Search(mixed, "cfg", "server", "host").Value(), "localhost", "Search mixed")
+}
+
+func Test_StructEmbedded(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type Inner struct {
+		A string `json:"a"`
+		B string `json:"b"`
+	}
+	type Named struct {
+		C string `json:"c"`
+	}
+	type Outer struct {
+		*Inner
+		Named `json:"named"`
+		B     string `json:"b"`
+	}
+
+	data := Outer{
+		Inner: &Inner{A: "inner-a", B: "inner-b"},
+		Named: Named{C: "named-c"},
+		B:     "outer-b",
+	}
+
+	assert(Get(data, "a"), "inner-a", "embedded field")
+	assert(Get(data, "b"), "outer-b", "dominant field")
+	assert(Get(data, "named.c"), "named-c", "tagged embedded field")
+
+	assert(Get(Outer{}, "a"), nil, "nil embedded pointer")
+
+	type inner struct {
+		X string `json:"x"`
+		y string
+	}
+	type Private struct {
+		inner `json:"in"`
+		Y     string `json:"y"`
+	}
+
+	private := Private{inner: inner{X: "in-x", y: "in-y"}, Y: "y"}
+
+	assert(Get(private, "y"), "y", "unexported tagged embedded sibling")
+	assert(Get(private, "in.x"), "in-x", "unexported tagged embedded field")
+	assert(Get(private, "in.y"), nil, "unexported tagged embedded private field")
+	assert(New(private).String(), `{"in":{"x":"in-x"},"y":"y"}`, "unexported tagged embedded ToJSON")
+	normalized, err := FromStruct(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(normalized.Value(), map[string]any{"in": map[string]any{"x": "in-x"}, "y": "y"}, "unexported tagged embedded FromStruct")
+
+	if _, err := New(&private).SetKey("z", "in.x"); err == nil {
+		t.Error("Set should error on unexported embedded field")
+	}
+}
+
+func Test_StructOmitEmpty(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type Server struct {
+		Host string `json:"host"`
+		Port int    `json:"port,omitempty"`
+	}
+
+	srv := &Server{Host: "h1", Port: 80}
+	arr := New(srv)
+
+	if _, err := arr.Set(0, "port"); err != nil {
+		t.Fatal(err)
+	}
+
+	value, found := arr.Lookup("port")
+	assert(value, 0, "Lookup omitempty field")
+	assert(found, true, "Lookup omitempty field found")
+	assert(arr.Has("port"), true, "Has omitempty field")
+	assert(len(arr.ChildrenMap()), 2, "ChildrenMap omitempty field")
+
+	assert(arr.String(), `{"host":"h1"}`, "ToJSON omits empty field")
+
+	flat, err := arr.Flatten()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(flat, map[string]any{"host": "h1"}, "Flatten omits empty field")
+
+	normalized, err := arr.Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(normalized.Value(), map[string]any{"host": "h1"}, "Normalize omits empty field")
+
+	err = arr.ApplyPatch([]byte(`[{"op": "replace", "path": "/port", "value": 8080}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(srv.Port, 8080, "JSON Patch replace omitempty field")
+}
+
+func Test_StructChildrenAndFlatten(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	data := testStructServer{
+		Host: "localhost",
+		Port: 8080,
+		Tags: []string{"a"},
+	}
+
+	childrenMap := New(data).ChildrenMap()
+	assert(len(childrenMap), 3, "ChildrenMap len")
+	assert(childrenMap["host"].Value(), "localhost", "ChildrenMap host")
+
+	assert(len(New(&data).Children()), 3, "Children len")
+
+	flattenData, err := New(map[string]any{"srv": &data}).Flatten()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := map[string]any{
+		"srv.host":   "localhost",
+		"srv.port":   8080,
+		"srv.Tags.0": "a",
+	}
+	assert(flattenData, check, "Flatten")
+}
+
+func Test_StructToJSON(t *testing.T) {
+	assert := assertT(t)
+
+	cfg := newTestStructConfig()
+	cfg.Extra = map[any]any{1: "one"}
+
+	check := `{"created":"2023-05-06T07:08:09Z","extra":{"1":"one"},"id":12,"name":"app","server":{"Tags":["a","b"],"host":"localhost","port":8080},"servers":{"prod":{"Tags":null,"host":"prod.host","port":443}}}`
+	assert(New(cfg).ToJSON(), check, "ToJSON")
+
+	var nilCfg *testStructConfig
+	assert(New(nilCfg).ToJSON(), "null", "ToJSON nil")
+}
+
+func Test_StructSet(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	cfg := newTestStructConfig()
+	arr := New(cfg)
+
+	if _, err := arr.SetKey("app2", "name"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetKey(int64(9090), "server.port"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetKey("c", "server.Tags.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetKey(33, "id"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetKey("new.host", "servers.prod.host"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetKey("nested-name", "nested.name"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arr.SetKey("p.host", "pointers.p1.host"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(cfg.Name, "app2", "Set name")
+	assert(cfg.Server.Port, 9090, "Set server.port")
+	assert(cfg.Server.Tags, []string{"a", "c"}, "Set server.Tags.1")
+	assert(cfg.ID, 33, "Set embedded id")
+	assert(cfg.Servers["prod"].Host, "new.host", "Set map struct value")
+	assert(cfg.Nested.Name, "nested-name", "Set nil pointer field")
+	assert(cfg.Pointers["p1"].Host, "p.host", "Set nil map field")
+
+	if _, err := arr.SetKey("x", "secret"); err == nil {
+		t.Error("Set should error on ignored field")
+	}
+	if _, err := arr.SetKey("x", "server.port"); err == nil {
+		t.Error("Set should error on type mismatch")
+	}
+
+	// =====
+
+	data := map[string]any{
+		"srv": testStructServer{Host: "h1"},
+	}
+
+	arr2 := New(data)
+	if _, err := arr2.SetKey(8, "srv.port"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(data["srv"].(testStructServer).Port, 8, "Set struct value in map")
+
+	arr3 := New(testStructServer{Host: "h1"})
+	if _, err := arr3.SetKey("h2", "host"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr3.Get("host"), "h2", "Set struct value root")
+}
+
+func Test_StructDelete(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	cfg := newTestStructConfig()
+	arr := New(cfg)
+
+	if err := arr.DeleteKey("server.host"); err != nil {
+		t.Fatal(err)
+	}
+	if err := arr.Delete("id"); err != nil {
+		t.Fatal(err)
+	}
+	if err := arr.DeleteKey("servers.prod.port"); err != nil {
+		t.Fatal(err)
+	}
+	if err := arr.DeleteKey("server.nothing"); err == nil {
+		t.Error("Delete should error on missing field")
+	}
+
+	assert(cfg.Server.Host, "", "Delete server.host")
+	assert(cfg.ID, 0, "Delete id")
+	assert(cfg.Servers["prod"].Port, 0, "Delete map struct value field")
+	assert(cfg.Servers["prod"].Host, "prod.host", "Delete map struct value other field")
+}
+
+type testStructNode struct {
+	Name   string          `json:"name"`
+	Parent *testStructNode `json:"parent,omitempty"`
+}
+
+func newTestStructCycle() *testStructNode {
+	n := &testStructNode{Name: "n"}
+	n.Parent = n
+
+	return n
+}
+
+func Test_StructCycle(t *testing.T) {
+	assert := assertT(t)
+
+	n := newTestStructCycle()
+
+	assert(New(n).String(), "null", "ToJSON cycle")
+
+	_, err := New(n).MarshalJSON()
+	assert(errors.Is(err, ErrCycle), "true", "MarshalJSON cycle")
+
+	_, err = New(n).Flatten()
+	assert(errors.Is(err, ErrCycle), "true", "Flatten cycle")
+
+	// 共享的指针不是循环引用
+	// shared pointers are not cycles
+	shared := &testStructNode{Name: "s"}
+	data := map[string]any{"a": shared, "b": shared}
+
+	assert(New(data).String(), `{"a":{"name":"s"},"b":{"name":"s"}}`, "ToJSON shared pointer")
+
+	flat, err := New(data).Flatten()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(len(flat), "2", "Flatten shared pointer")
+}
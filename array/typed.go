@@ -0,0 +1,353 @@
+package array
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 获取指定类型数据
+// GetAs returns the value of key converted to T. The default is returned
+// when the key is not found. Lenient Arrays convert across numeric kinds,
+// numeric strings and toString compatible types, strict Arrays (see
+// WithStrict) only convert numbers without loss and return an error
+// *PathError wrapping ErrTypeMismatch otherwise.
+func GetAs[T any](a *Array, key string, def ...T) (T, error) {
+	var zero T
+	if len(def) > 0 {
+		zero = def[0]
+	}
+
+	data := a.Find(key)
+	if data == nil {
+		return zero, nil
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	res, err := convertAs(data, typ, a.strict)
+	if err != nil {
+		return zero, &PathError{
+			Op:      "get",
+			Path:    key,
+			Segment: -1,
+			Err:     fmt.Errorf("%w: %s", ErrTypeMismatch, err.Error()),
+		}
+	}
+
+	return res.Interface().(T), nil
+}
+
+// 获取字符串
+// GetString returns the value of key as string
+func (this *Array) GetString(key string, def ...string) (string, error) {
+	return GetAs[string](this, key, def...)
+}
+
+// 获取 int
+// GetInt returns the value of key as int
+func (this *Array) GetInt(key string, def ...int) (int, error) {
+	return GetAs[int](this, key, def...)
+}
+
+// 获取 int64
+// GetInt64 returns the value of key as int64
+func (this *Array) GetInt64(key string, def ...int64) (int64, error) {
+	return GetAs[int64](this, key, def...)
+}
+
+// 获取 uint
+// GetUint returns the value of key as uint
+func (this *Array) GetUint(key string, def ...uint) (uint, error) {
+	return GetAs[uint](this, key, def...)
+}
+
+// 获取 float64
+// GetFloat64 returns the value of key as float64
+func (this *Array) GetFloat64(key string, def ...float64) (float64, error) {
+	return GetAs[float64](this, key, def...)
+}
+
+// 获取 bool
+// GetBool returns the value of key as bool
+func (this *Array) GetBool(key string, def ...bool) (bool, error) {
+	return GetAs[bool](this, key, def...)
+}
+
+// 获取字符串切片
+// GetStringSlice returns the value of key as []string
+func (this *Array) GetStringSlice(key string, def ...[]string) ([]string, error) {
+	return GetAs[[]string](this, key, def...)
+}
+
+// 获取字符串 map
+// GetStringMap returns the value of key as map[string]any
+func (this *Array) GetStringMap(key string, def ...map[string]any) (map[string]any, error) {
+	return GetAs[map[string]any](this, key, def...)
+}
+
+// 转换数据类型
+// convert the value to typ
+func convertAs(value any, typ reflect.Type, strict bool) (reflect.Value, error) {
+	if value == nil {
+		if canBeNil(typ) {
+			return reflect.Zero(typ), nil
+		}
+
+		return reflect.Value{}, fmt.Errorf("null is not %s", typ)
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Type().AssignableTo(typ) {
+		return val, nil
+	}
+
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+		if val.Type().AssignableTo(typ) {
+			return val, nil
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		if strict {
+			if val.Kind() == reflect.String {
+				return val.Convert(typ), nil
+			}
+
+			break
+		}
+
+		if s, ok := toStringE(value); ok {
+			return reflect.ValueOf(s).Convert(typ), nil
+		}
+	case reflect.Bool:
+		if val.Kind() == reflect.Bool {
+			return val.Convert(typ), nil
+		}
+
+		if strict {
+			break
+		}
+
+		if s, ok := toStringE(value); ok && val.Kind() == reflect.String {
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return reflect.ValueOf(b).Convert(typ), nil
+			}
+		}
+
+		if isNumberKind(val.Kind()) {
+			return reflect.ValueOf(!val.IsZero()).Convert(typ), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if num, ok := numberValue(val, strict); ok {
+			return convertNumber(num, typ, strict)
+		}
+	case reflect.Slice:
+		if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+			break
+		}
+
+		res := reflect.MakeSlice(typ, val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			elem, err := convertAs(val.Index(i).Interface(), typ.Elem(), strict)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("index %d: %s", i, err.Error())
+			}
+
+			res.Index(i).Set(elem)
+		}
+
+		return res, nil
+	case reflect.Map:
+		if val.Kind() != reflect.Map || typ.Key().Kind() != reflect.String {
+			break
+		}
+
+		res := reflect.MakeMapWithSize(typ, val.Len())
+
+		iter := val.MapRange()
+		for iter.Next() {
+			key := toString(iter.Key().Interface())
+
+			elem, err := convertAs(iter.Value().Interface(), typ.Elem(), strict)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key '%s': %s", key, err.Error())
+			}
+
+			res.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), elem)
+		}
+
+		return res, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("%T '%v' is not %s", value, value, typ)
+}
+
+// 获取数字，宽松模式支持数字字符串
+// returns the number of val as int64, uint64 or float64, lenient mode
+// parses numeric strings
+func numberValue(val reflect.Value, strict bool) (any, bool) {
+	if n, ok := val.Interface().(json.Number); ok {
+		return parseNumber(n.String())
+	}
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return val.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), true
+	case reflect.String:
+		if !strict {
+			return parseNumber(strings.TrimSpace(val.String()))
+		}
+	}
+
+	return nil, false
+}
+
+func parseNumber(s string) (any, bool) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, true
+	}
+
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, true
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+
+	return nil, false
+}
+
+// 转换数字，检测溢出，严格模式不允许丢失小数
+// convert the number to typ with overflow detection, strict mode fails
+// on fractional floats
+func convertNumber(num any, typ reflect.Type, strict bool) (reflect.Value, error) {
+	res := reflect.New(typ).Elem()
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+
+		switch n := num.(type) {
+		case int64:
+			i = n
+		case uint64:
+			if n > math.MaxInt64 {
+				return reflect.Value{}, fmt.Errorf("%v overflows %s", num, typ)
+			}
+
+			i = int64(n)
+		case float64:
+			if strict && n != math.Trunc(n) {
+				return reflect.Value{}, fmt.Errorf("%v is not an integer", num)
+			}
+
+			if math.IsNaN(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+				return reflect.Value{}, fmt.Errorf("%v overflows %s", num, typ)
+			}
+
+			i = int64(n)
+		}
+
+		if res.OverflowInt(i) {
+			return reflect.Value{}, fmt.Errorf("%v overflows %s", num, typ)
+		}
+
+		res.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+
+		switch n := num.(type) {
+		case int64:
+			if n < 0 {
+				return reflect.Value{}, fmt.Errorf("%v overflows %s", num, typ)
+			}
+
+			u = uint64(n)
+		case uint64:
+			u = n
+		case float64:
+			if strict && n != math.Trunc(n) {
+				return reflect.Value{}, fmt.Errorf("%v is not an integer", num)
+			}
+
+			if math.IsNaN(n) || n < 0 || n >= math.MaxUint64 {
+				return reflect.Value{}, fmt.Errorf("%v overflows %s", num, typ)
+			}
+
+			u = uint64(n)
+		}
+
+		if res.OverflowUint(u) {
+			return reflect.Value{}, fmt.Errorf("%v overflows %s", num, typ)
+		}
+
+		res.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+
+		switch n := num.(type) {
+		case int64:
+			f = float64(n)
+		case uint64:
+			f = float64(n)
+		case float64:
+			f = n
+		}
+
+		if res.OverflowFloat(f) {
+			return reflect.Value{}, fmt.Errorf("%v overflows %s", num, typ)
+		}
+
+		res.SetFloat(f)
+	}
+
+	return res, nil
+}
+
+// 检测数字转为整数时是否丢失小数
+// returns an error when the number data loses its fraction as an integer
+// of typ
+func checkExactNumber(data any, typ reflect.Type) error {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return nil
+	}
+
+	val := reflect.ValueOf(data)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+
+	num, _ := numberValue(val, false)
+	if f, ok := num.(float64); ok && f != math.Trunc(f) {
+		return fmt.Errorf("%v is not an integer", data)
+	}
+
+	return nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package array
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test_GetAs(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr, _ := ParseJSON([]byte(`{
+		"a": {"int": 12, "float": 12.5, "str": "42", "bool": "true", "neg": -3, "big": 1e20},
+		"list": ["x", 1, true],
+		"m": {"k": 1}
+	}`))
+
+	i, err := GetAs[int](arr, "a.int")
+	assert(i, 12, "GetAs int")
+	assert(err, nil, "GetAs int err")
+
+	i, _ = GetAs[int](arr, "a.float")
+	assert(i, 12, "GetAs int from float")
+
+	i, _ = GetAs[int](arr, "a.str")
+	assert(i, 42, "GetAs int from string")
+
+	i8, err := GetAs[int8](arr, "a.big")
+	assert(i8, int8(0), "GetAs int8 overflow")
+	assert(errors.Is(err, ErrTypeMismatch), true, "GetAs int8 overflow err")
+
+	u, err := arr.GetUint("a.neg", 7)
+	assert(u, uint(7), "GetUint negative")
+	assert(errors.Is(err, ErrTypeMismatch), true, "GetUint negative err")
+
+	s, _ := arr.GetString("a.float")
+	assert(s, "12.5", "GetString")
+
+	b, _ := arr.GetBool("a.bool")
+	assert(b, true, "GetBool")
+
+	f, _ := arr.GetFloat64("a.str")
+	assert(f, float64(42), "GetFloat64")
+
+	i64, _ := arr.GetInt64("a.int")
+	assert(i64, int64(12), "GetInt64")
+
+	ss, _ := arr.GetStringSlice("list")
+	assert(ss, []string{"x", "1", "true"}, "GetStringSlice")
+
+	sm, _ := arr.GetStringMap("m")
+	assert(sm, map[string]any{"k": float64(1)}, "GetStringMap")
+
+	s, err = arr.GetString("a.missing", "def")
+	assert(s, "def", "GetString default")
+	assert(err, nil, "GetString default err")
+
+	_, err = arr.GetInt("m")
+	assert(errors.Is(err, ErrTypeMismatch), true, "GetInt map err")
+
+	im, _ := GetAs[map[string]int](New(map[any]any{"m": map[any]any{1: 2.0}}), "m")
+	assert(im, map[string]int{"1": 2}, "GetAs map[string]int")
+
+	n, _ := GetAs[int](New(map[string]any{"n": json.Number("5")}), "n")
+	assert(n, 5, "GetAs json.Number")
+}
+
+func Test_GetAs_Strict(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr := New(map[string]any{
+		"int":   12,
+		"float": 12.0,
+		"frac":  12.5,
+		"str":   "42",
+		"bool":  true,
+	}).WithStrict(true)
+
+	i, err := arr.GetInt("float")
+	assert(i, 12, "strict int from integral float")
+	assert(err, nil, "strict int from integral float err")
+
+	f, _ := arr.GetFloat64("int")
+	assert(f, float64(12), "strict float from int")
+
+	_, err = arr.GetInt("frac")
+	assert(errors.Is(err, ErrTypeMismatch), true, "strict fractional float err")
+
+	_, err = arr.GetInt("str")
+	assert(errors.Is(err, ErrTypeMismatch), true, "strict string err")
+
+	_, err = arr.GetString("int")
+	assert(errors.Is(err, ErrTypeMismatch), true, "strict int to string err")
+	assert(err.Error(), "get 'int': type mismatch: int '12' is not string", "strict error message")
+
+	sub := New(map[string]any{"a": map[string]any{"b": "1"}}).WithStrict(true).Sub("a")
+	_, err = sub.GetInt("b")
+	assert(errors.Is(err, ErrTypeMismatch), true, "strict inherited by Sub err")
+}
@@ -6,8 +6,74 @@ import (
 	"html/template"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
+// 扩展切片时最多填充的空数据数量，更大的索引不会分配切片
+// the max number of gaps filled when a slice is grown to an index,
+// larger indexes do not allocate a slice
+const maxSliceGrowth = 1 << 16
+
+// 循环引用检测，记录当前路径上的指针、map 和切片，和 encoding/json 一致
+// cycleDetector records the pointers, maps and slices on the current path
+// to detect cycles like encoding/json
+type cycleDetector map[cycleKey]struct{}
+
+type cycleKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// 进入数据，数据已在当前路径上时返回 false
+// enter v, returns false when v is already on the current path
+func (this cycleDetector) enter(v reflect.Value) bool {
+	key, ok := newCycleKey(v)
+	if !ok {
+		return true
+	}
+
+	if _, seen := this[key]; seen {
+		return false
+	}
+
+	this[key] = struct{}{}
+	return true
+}
+
+// 离开数据
+// leave v after it is walked
+func (this cycleDetector) leave(v reflect.Value) {
+	if key, ok := newCycleKey(v); ok {
+		delete(this, key)
+	}
+}
+
+// 循环引用错误
+// returns the ErrCycle error of v
+func cycleError(v reflect.Value) error {
+	return fmt.Errorf("%w via %s", ErrCycle, v.Type())
+}
+
+func newCycleKey(v reflect.Value) (cycleKey, bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map:
+		if v.IsNil() {
+			return cycleKey{}, false
+		}
+
+		return cycleKey{v.Pointer(), v.Type(), 0}, true
+	case reflect.Slice:
+		if v.IsNil() || v.Len() == 0 {
+			return cycleKey{}, false
+		}
+
+		return cycleKey{v.Pointer(), v.Type(), v.Len()}, true
+	}
+
+	return cycleKey{}, false
+}
+
 // 转为数组
 func toStringMap(i any) map[string]any {
 	var m = map[string]any{}
@@ -15,7 +81,7 @@ func toStringMap(i any) map[string]any {
 	switch v := i.(type) {
 	case map[any]any:
 		for k, val := range v {
-			m[toString(k)] = val
+			m[mapKeyString(k)] = val
 		}
 		return m
 	case map[string]any:
@@ -29,38 +95,45 @@ func toStringMap(i any) map[string]any {
 }
 
 func toString(i any) string {
+	s, _ := toStringE(i)
+	return s
+}
+
+// 转换为字符串，返回是否可转换
+// convert to string and report if the type can be converted
+func toStringE(i any) (string, bool) {
 	i = indirectToStringerOrError(i)
 
 	res, ok := toIntString(i)
 	if ok {
-		return res
+		return res, true
 	}
 
 	switch s := i.(type) {
 	case []byte:
-		return string(s)
+		return string(s), true
 	case string:
-		return s
+		return s, true
 	case bool:
-		return strconv.FormatBool(s)
+		return strconv.FormatBool(s), true
 	case template.HTML:
-		return string(s)
+		return string(s), true
 	case template.URL:
-		return string(s)
+		return string(s), true
 	case template.JS:
-		return string(s)
+		return string(s), true
 	case template.CSS:
-		return string(s)
+		return string(s), true
 	case template.HTMLAttr:
-		return string(s)
+		return string(s), true
 	case nil:
-		return ""
+		return "", true
 	case fmt.Stringer:
-		return s.String()
+		return s.String(), true
 	case error:
-		return s.Error()
+		return s.Error(), true
 	default:
-		return ""
+		return "", false
 	}
 }
 
@@ -137,3 +210,232 @@ func formatPathString(path []any) []string {
 
 	return p
 }
+
+// 判断是否可为 nil
+// if the type can be nil
+func canBeNil(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface,
+		reflect.Map, reflect.Pointer, reflect.Slice:
+		return true
+	}
+
+	return false
+}
+
+// 判断是否为 nil 值
+// if the value is nil
+func isNilValue(v reflect.Value) bool {
+	if !canBeNil(v.Type()) {
+		return false
+	}
+
+	return v.IsNil()
+}
+
+// 创建路径中间数据
+// new container value for the path
+func newPathValue(typ reflect.Type) reflect.Value {
+	switch typ.Kind() {
+	case reflect.Interface:
+		return reflect.ValueOf(map[string]any{})
+	case reflect.Map:
+		return reflect.MakeMap(typ)
+	case reflect.Pointer:
+		return reflect.New(typ.Elem())
+	case reflect.Slice:
+		return reflect.MakeSlice(typ, 0, 0)
+	}
+
+	return reflect.New(typ).Elem()
+}
+
+// 判断类型的数据是否可以包含子级路径
+// if values of typ can hold child path segments
+func isPathContainerType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		return typ.NumMethod() == 0
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	}
+
+	return false
+}
+
+// 深度复制，共享和循环引用的指针、map 和切片在副本中保持一致
+// deep copy the value, keeps the source types, shared and cyclic
+// pointers, maps and slices are shared and cyclic in the copy too
+func deepCopy(src any) any {
+	if src == nil {
+		return nil
+	}
+
+	return deepCopyValue(reflect.ValueOf(src), map[cycleKey]reflect.Value{}).Interface()
+}
+
+func deepCopyValue(v reflect.Value, copied map[cycleKey]reflect.Value) reflect.Value {
+	key, ok := newCycleKey(v)
+	if ok {
+		if dst, ok := copied[key]; ok {
+			return dst
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		dst := reflect.New(v.Type().Elem())
+		copied[key] = dst
+
+		dst.Elem().Set(deepCopyValue(v.Elem(), copied))
+
+		return dst
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		dst := reflect.New(v.Type()).Elem()
+		dst.Set(deepCopyValue(v.Elem(), copied))
+
+		return dst
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		dst := reflect.MakeMapWithSize(v.Type(), v.Len())
+		copied[key] = dst
+
+		iter := v.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), deepCopyValue(iter.Value(), copied))
+		}
+
+		return dst
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		dst := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		if ok {
+			copied[key] = dst
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(deepCopyValue(v.Index(i), copied))
+		}
+
+		return dst
+	case reflect.Array:
+		dst := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(deepCopyValue(v.Index(i), copied))
+		}
+
+		return dst
+	case reflect.Struct:
+		dst := reflect.New(v.Type()).Elem()
+		dst.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(deepCopyValue(v.Field(i), copied))
+			}
+		}
+
+		return dst
+	}
+
+	return v
+}
+
+// 判断是否为切片索引路径，包括追加标记 `-`
+// if the segment is a non-negative array index or the append marker `-`
+func isIndexSegment(seg string) bool {
+	if seg == "-" {
+		return true
+	}
+
+	index, err := strconv.Atoi(seg)
+
+	return err == nil && index >= 0 && strconv.Itoa(index) == seg
+}
+
+// 格式化索引，负数从末尾计算
+// normalize the index, a negative index counts from the end
+func normalizeIndex(index, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+
+	return index, index >= 0 && index < length
+}
+
+// 解析切片片段，如 `1:3`, `:2` 和 `-2:`
+// parse slice segment like `1:3`, `:2` and `-2:`, the bounds are
+// clamped like python
+func parseSliceSegment(seg string, length int) (int, int, bool) {
+	startStr, endStr, ok := strings.Cut(seg, ":")
+	if !ok {
+		return 0, 0, false
+	}
+
+	bound := func(s string, def int) (int, bool) {
+		if s == "" {
+			return def, true
+		}
+
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, false
+		}
+
+		if n < 0 {
+			n += length
+		}
+
+		if n < 0 {
+			n = 0
+		} else if n > length {
+			n = length
+		}
+
+		return n, true
+	}
+
+	start, ok := bound(startStr, 0)
+	if !ok {
+		return 0, 0, false
+	}
+
+	end, ok := bound(endStr, length)
+	if !ok {
+		return 0, 0, false
+	}
+
+	if end < start {
+		end = start
+	}
+
+	return start, end, true
+}
+
+// 在路径前添加键名，nil 路径保持为 nil
+// prepend key to the path, a nil path stays nil
+func prependPath(key string, path []string) []string {
+	if path == nil {
+		return nil
+	}
+
+	return append([]string{key}, path...)
+}
@@ -0,0 +1,174 @@
+package array
+
+// 创建子级数据，记录父级和路径，修改时在父级的当前数据上修改
+// returns a child Array of source at path, the child remembers this as
+// its parent and applies its changes to the current data of it
+func (this *Array) child(source any, path []string) *Array {
+	arr := this.derive(source)
+
+	// 没有路径时数据为复制，不回写
+	// a nil path means the source is a copy that is not written back
+	if path == nil {
+		return arr
+	}
+
+	// 多段路径时逐段创建中间数据，父级为直接包含的数据
+	// a path of several segments links an Array for every segment, so the
+	// parent is the node that directly contains the child
+	parent := this
+	for len(path) > 1 {
+		value, _, found := parent.search(parent.source, path[0])
+
+		next := parent.derive(value)
+		next.parent = parent
+		next.path = path[:1:1]
+		next.missing = !found
+
+		parent, path = next, path[1:]
+	}
+
+	arr.parent = parent
+	arr.path = path
+
+	return arr
+}
+
+// 创建不存在的子级数据，设置数据时在父级创建
+// returns a missing child Array at path, setting data on it creates the
+// path in the parent
+func (this *Array) missingChild(path []string) *Array {
+	arr := this.child(nil, path)
+	arr.missing = true
+
+	return arr
+}
+
+// 在父级的当前数据上设置，然后重新读取数据
+// set the value on the current data of the parent with the path of this
+// Array prepended, then read the source back
+func (this *Array) setParentPath(value any, path []any) (*Array, error) {
+	fullPath := append(formatPath(this.path), path...)
+
+	var res *Array
+	var err error
+	if this.parent.parent != nil {
+		res, err = this.parent.setParentPath(value, fullPath)
+	} else {
+		res, err = this.parent.setSourcePath(value, fullPath)
+	}
+
+	this.reload()
+
+	if err != nil {
+		return nil, this.parentError(err, path)
+	}
+
+	if len(path) == 0 {
+		return this, nil
+	}
+
+	return this.child(res.source, formatPathString(path)), nil
+}
+
+// 在父级的当前数据上删除，然后重新读取数据
+// delete from the current data of the parent with the path of this Array
+// prepended, then read the source back
+func (this *Array) deleteParentPath(path []any) error {
+	fullPath := append(formatPath(this.path), path...)
+
+	var err error
+	if this.parent.parent != nil {
+		err = this.parent.deleteParentPath(fullPath)
+	} else {
+		err = this.parent.deleteSourcePath(fullPath)
+	}
+
+	this.reload()
+
+	return this.parentError(err, path)
+}
+
+// 从父级重新读取当前数据，直到根数据
+// read the current data back from the parents up to the root
+func (this *Array) refresh() {
+	if this.parent == nil {
+		return
+	}
+
+	this.parent.refresh()
+	this.reload()
+}
+
+func (this *Array) reload() {
+	source, _, found := this.parent.search(this.parent.source, this.path...)
+
+	this.source = source
+	this.missing = !found
+}
+
+// 父级的路径错误转为相对于自身的路径
+// convert the PathError of the parent to the path of this Array, errors
+// in the path of this Array are returned as they are
+func (this *Array) parentError(err error, path []any) error {
+	pathErr, ok := err.(*PathError)
+	if !ok {
+		return err
+	}
+
+	segment := -1
+	if pathErr.Segment >= 0 {
+		segment = pathErr.Segment - len(this.path)
+		if segment < 0 {
+			return err
+		}
+	}
+
+	return this.pathError(pathErr.Op, path, segment, pathErr.Err, "")
+}
+
+// 获取根数据的路径
+// Path returns the path of the Array from the root, the root and Arrays
+// copied by slice segments return an empty path
+func (this *Array) Path() []string {
+	if this.parent == nil {
+		return []string{}
+	}
+
+	return append(this.parent.Path(), this.path...)
+}
+
+// 获取根数据的 JSON Pointer
+// Pointer returns the JSON Pointer of the Array from the root
+func (this *Array) Pointer() string {
+	return SliceToJSONPointer(this.Path())
+}
+
+// 获取在父级中的键名
+// Key returns the key or index of the Array in its parent, the root
+// returns an empty string
+func (this *Array) Key() string {
+	if len(this.path) == 0 {
+		return ""
+	}
+
+	return this.path[len(this.path)-1]
+}
+
+// 获取父级数据
+// Parent returns the Array that directly contains this one, or nil for
+// the root, the parents of a Sub with several segments are linked for
+// every segment
+func (this *Array) Parent() *Array {
+	return this.parent
+}
+
+// 获取根数据
+// Root returns the root Array this one is derived from
+func (this *Array) Root() *Array {
+	root := this
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	return root
+}
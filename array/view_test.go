@@ -0,0 +1,255 @@
+package array
+
+import (
+	"testing"
+)
+
+func Test_LiveView(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type Server struct {
+		Host string
+		Port int
+	}
+
+	data := map[string]any{
+		"b":      map[string]any{"hh": map[int]any{1: "a"}},
+		"ints":   []int{1, 2},
+		"counts": map[string]int{"a": 1, "b": 2},
+		"server": Server{Host: "localhost"},
+		"fixed":  [2]string{"x", "y"},
+		"items":  []any{"i0", "i1", "i2"},
+		"deep":   map[string]any{"a": map[string]any{}},
+	}
+
+	arr := New(data)
+
+	if _, err := arr.Sub("b.hh").Set("x", "1115"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("b.hh.1115"), "x", "Set on typed map view")
+
+	if _, err := arr.Sub("ints").Set(3, "-"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["ints"], []int{1, 2, 3}, "Append on typed slice view")
+
+	if err := arr.Sub("counts").Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["counts"], map[string]int{"b": 2}, "Delete on typed map view")
+
+	if _, err := arr.Sub("server").Set(80, "Port"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["server"], Server{Host: "localhost", Port: 80}, "Set on struct view")
+
+	if _, err := arr.Sub("fixed").SetIndex("z", 1); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["fixed"], [2]string{"x", "z"}, "SetIndex on array view")
+
+	if _, err := arr.Sub("deep").Sub("a").Set(1, "b"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("deep.a.b"), 1, "Set on nested view")
+
+	if _, err := arr.Sub("items").Index(-1).Set("I2"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["items"], []any{"i0", "i1", "I2"}, "Set root of Index view")
+
+	for _, child := range arr.Sub("counts").Children() {
+		if _, err := child.Set(child.Value().(int) * 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert(data["counts"], map[string]int{"b": 20}, "Set on Children view")
+
+	if _, err := arr.Sub("new.x").Set(1, "y"); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("new.x.y"), 1, "Set on missing view")
+
+	if _, err := arr.Sub("items.0:2").SetIndex("z", 0); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["items"], []any{"i0", "i1", "I2"}, "Slice segment view is a copy")
+
+	if err := arr.Sub("deep").Merge([]*Array{New(map[string]any{"c": 2})}); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("deep.c"), 2, "Merge on view")
+
+	if err := arr.Sub("server").ApplyPatch([]byte(`[{"op":"replace","path":"/Host","value":"example.com"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["server"].(Server).Host, "example.com", "ApplyPatch on view")
+
+	for _, v := range arr.SubAll("ints.*") {
+		if _, err := v.Set(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert(data["ints"], []int{0, 0, 0}, "Set on SubAll view")
+}
+
+func Test_LiveViewInterfaceKeys(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	anys := map[any]any{1: "a", 2: map[any]any{true: 1}}
+	arr := New(anys)
+
+	if _, err := arr.Sub("1").Set("b"); err != nil {
+		t.Fatal(err)
+	}
+	assert(anys, map[any]any{1: "b", 2: map[any]any{true: 1}}, "Set on interface key view")
+
+	if _, err := arr.Sub("2").Set(2, "true"); err != nil {
+		t.Fatal(err)
+	}
+	assert(anys[2], map[any]any{true: 2}, "Set on nested interface key view")
+
+	for _, child := range arr.Children() {
+		if s, ok := child.Value().(string); ok {
+			if _, err := child.Set(s + "c"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	assert(len(anys), 2, "Set on Children interface key view len")
+	assert(anys[1], "bc", "Set on Children interface key view")
+
+	for _, child := range arr.Sub("2").ChildrenMap() {
+		if _, err := child.Set(3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert(anys[2], map[any]any{true: 3}, "Set on ChildrenMap interface key view")
+}
+
+func Test_LiveViewSiblings(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	type Server struct {
+		Host string
+		Port int
+	}
+
+	data := map[string]any{
+		"server": Server{},
+		"a":      map[string]any{"old": 1},
+		"l":      []any{"x"},
+		"d":      map[int]any{1: "a", 2: "b"},
+	}
+
+	arr := New(data)
+
+	c1 := arr.Sub("server")
+	c2 := arr.Sub("server")
+	if _, err := c1.Set("A", "Host"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c2.Set(2, "Port"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["server"], Server{Host: "A", Port: 2}, "Set on sibling struct views")
+
+	stale := arr.Sub("a")
+	if _, err := arr.Set(map[string]any{"new": 2}, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stale.Set(3, "b"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["a"], map[string]any{"new": 2, "b": 3}, "Set on stale view")
+
+	f := arr.Sub("l")
+	if _, err := arr.Set("y", "l", "-"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.SetIndex("z", 0); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["l"], []any{"z", "y"}, "SetIndex on view after append")
+
+	d1 := arr.Sub("d")
+	d2 := arr.Sub("d")
+	if err := d1.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d2.Set("c", "3"); err != nil {
+		t.Fatal(err)
+	}
+	assert(data["d"], map[int]any{2: "b", 3: "c"}, "Delete and Set on sibling map views")
+}
+
+func Test_Navigation(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr := New(map[string]any{
+		"a": map[string]any{
+			"b/c": []any{
+				map[string]any{"name": "x"},
+				map[string]any{"name": "y"},
+			},
+		},
+		"DBHost": "localhost",
+	}, WithCaseInsensitive(true))
+
+	assert(arr.Path(), []string{}, "root Path")
+	assert(arr.Pointer(), "", "root Pointer")
+	assert(arr.Key(), "", "root Key")
+	assert(arr.Parent() == nil, true, "root Parent")
+	assert(arr.Root() == arr, true, "root Root")
+
+	sub := arr.Sub("a")
+	list := sub.Search("b/c")
+	item := list.Index(-1)
+	name := item.Sub("name")
+
+	assert(name.Path(), []string{"a", "b/c", "1", "name"}, "Path")
+	assert(name.Pointer(), "/a/b~1c/1/name", "Pointer")
+	assert(name.Key(), "name", "Key")
+	assert(item.Key(), "1", "Index Key")
+	assert(name.Parent() == item, true, "Parent")
+	assert(name.Root() == arr, true, "Root")
+
+	assert(arr.Sub("a.b/c.0.name").Path(), []string{"a", "b/c", "0", "name"}, "Sub Path")
+	assert(arr.Sub("dbhost").Key(), "DBHost", "Key original spelling")
+	assert(arr.Sub("a.missing").Path(), []string{"a", "missing"}, "missing Path")
+
+	deep := arr.Sub("a.b/c.0.name")
+	assert(deep.Parent().Key(), "0", "Sub Parent")
+	assert(deep.Parent().Parent().Value(), arr.Get("a.b/c"), "Sub Parent value")
+	assert(deep.Parent().Parent().Parent().Parent() == arr, true, "Sub Parent root")
+
+	missing := arr.Sub("x.y.z")
+	assert(missing.Parent().Path(), []string{"x", "y"}, "missing Parent Path")
+	assert(missing.Parent().Found(), false, "missing Parent Found")
+	if _, err := missing.Set(1); err != nil {
+		t.Fatal(err)
+	}
+	assert(arr.Get("x.y.z"), 1, "Set on missing Sub")
+
+	for i, child := range list.Children() {
+		assert(child.Pointer(), "/a/b~1c/"+toString(i), "Children Pointer")
+	}
+
+	for k, child := range sub.ChildrenMap() {
+		assert(child.Key(), k, "ChildrenMap Key")
+		assert(child.Parent() == sub, true, "ChildrenMap Parent")
+	}
+
+	res, err := arr.Set("z", "a", "b/c", "-", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(res.Pointer(), "/a/b~1c/2/name", "Set Pointer")
+
+	nodes := arr.SubAll("a.b/c.*.name")
+	assert(len(nodes), 3, "SubAll len")
+	assert(nodes[0].Path(), []string{"a", "b/c", "0", "name"}, "SubAll Path")
+
+	assert(arr.Sub("a.b/c.0:1").Path(), []string{}, "slice segment Path")
+}
@@ -0,0 +1,304 @@
+package array
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// 通配符
+// wildcard path segments
+const (
+	wildcardAny   = "*"
+	wildcardDepth = "**"
+)
+
+// 使用通配符获取数据
+// SubAll returns every value matched by the key, where `*` matches any
+// single key or index, `**` matches any depth and shell-style globs
+// like `prod-*` match key names
+func (this *Array) SubAll(key string) []*Array {
+	path := KeyDelimPathToSlice(key, this.keyDelim)
+
+	return this.SearchAll(path...)
+}
+
+// 使用通配符获取数据
+// SubAll returns every value from source matched by the key
+func SubAll(source any, key string) []*Array {
+	return New(source).SubAll(key)
+}
+
+// 使用通配符搜索数据
+// SearchAll returns every value matched by the path
+func (this *Array) SearchAll(path ...string) []*Array {
+	nodes := this.matchNodes(path)
+
+	res := make([]*Array, 0, len(nodes))
+	for _, node := range nodes {
+		res = append(res, this.child(node.value, append([]string{}, node.path...)))
+	}
+
+	return res
+}
+
+// 使用通配符获取数据，返回实际路径和数据
+// GetAll returns the values matched by the key, the map key is the
+// resolved path joined with keyDelim
+func (this *Array) GetAll(key string) map[string]any {
+	path := KeyDelimPathToSlice(key, this.keyDelim)
+
+	res := make(map[string]any)
+	for _, node := range this.matchNodes(path) {
+		res[KeyDelimSliceToPath(node.path, this.keyDelim)] = node.value
+	}
+
+	return res
+}
+
+// 使用通配符获取数据，返回实际路径和数据
+// GetAll returns the values from source matched by the key
+func GetAll(source any, key string) map[string]any {
+	return New(source).GetAll(key)
+}
+
+// 使用通配符设置数据
+// SetKeyAll sets value at every path matched by the key
+func (this *Array) SetKeyAll(value any, key string) ([]*Array, error) {
+	path := KeyDelimPathToSlice(key, this.keyDelim)
+
+	this.refresh()
+	nodes := this.matchNodes(path)
+
+	res := make([]*Array, 0, len(nodes))
+	for _, node := range nodes {
+		arr, err := this.setPath(value, formatPath(node.path)...)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, arr)
+	}
+
+	return res, nil
+}
+
+// 使用通配符删除数据
+// DeleteKeyAll deletes every path matched by the key
+func (this *Array) DeleteKeyAll(key string) error {
+	path := KeyDelimPathToSlice(key, this.keyDelim)
+
+	this.refresh()
+	nodes := this.matchNodes(path)
+
+	// 倒序删除，保证切片索引不变
+	// delete in reverse order so slice indexes keep valid
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if err := this.deletePath(formatPath(nodes[i].path)...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 匹配路径
+// match nodes with wildcard path
+func (this *Array) matchNodes(path []string) []queryNode {
+	res := make([]queryNode, 0)
+	seen := make(map[string]bool)
+
+	this.matchNode(queryNode{value: this.source}, path, cycleDetector{}, func(node queryNode) {
+		key := strings.Join(node.path, "\x00")
+		if !seen[key] {
+			seen[key] = true
+			res = append(res, node)
+		}
+	})
+
+	return res
+}
+
+func (this *Array) matchNode(node queryNode, path []string, seen cycleDetector, yield func(queryNode)) {
+	if len(path) == 0 {
+		yield(node)
+		return
+	}
+
+	seg := path[0]
+
+	switch {
+	case seg == wildcardDepth:
+		// 连续的 `**` 等同于一个，循环引用的节点跳过
+		// repeated `**` match like one, nodes of cycles are skipped
+		for len(path) > 1 && path[1] == wildcardDepth {
+			path = path[1:]
+		}
+
+		value := reflect.ValueOf(node.value)
+		if !seen.enter(value) {
+			return
+		}
+		defer seen.leave(value)
+
+		this.matchNode(node, path[1:], seen, yield)
+
+		for _, child := range this.queryChildren(node) {
+			this.matchNode(child, path, seen, yield)
+		}
+	case isGlobPattern(seg):
+		for _, child := range this.queryChildren(node) {
+			key := child.path[len(child.path)-1]
+			if seg == wildcardAny || key == seg || globMatch(seg, key) {
+				this.matchNode(child, path[1:], seen, yield)
+			}
+		}
+	default:
+		for _, child := range this.queryChildren(node) {
+			if child.path[len(child.path)-1] == seg {
+				this.matchNode(child, path[1:], seen, yield)
+				break
+			}
+		}
+	}
+}
+
+// 判断路径是否匹配通配符路径
+// if the concrete path matches the wildcard pattern
+func matchPathPattern(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	seg := pattern[0]
+
+	if seg == wildcardDepth {
+		for i := 0; i <= len(path); i++ {
+			if matchPathPattern(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+
+	if seg != path[0] && !(isGlobPattern(seg) && (seg == wildcardAny || globMatch(seg, path[0]))) {
+		return false
+	}
+
+	return matchPathPattern(pattern[1:], path[1:])
+}
+
+// 判断是否为通配符
+// if the segment has glob meta characters
+func isGlobPattern(seg string) bool {
+	return strings.ContainsAny(seg, "*?[")
+}
+
+// shell 风格匹配，支持 `*`, `?`, `[a-z]`, `[!a-z]` 和 `\` 转义
+// shell-style match with `*`, `?`, `[a-z]`, `[!a-z]` and `\` escape
+func globMatch(pattern, name string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+
+			if len(pattern) == 0 {
+				return true
+			}
+
+			for i := 0; i <= len(name); i++ {
+				if globMatch(pattern, name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		case '?':
+			if len(name) == 0 {
+				return false
+			}
+
+			_, size := utf8.DecodeRuneInString(name)
+			pattern, name = pattern[1:], name[size:]
+		case '[':
+			if len(name) == 0 {
+				return false
+			}
+
+			r, size := utf8.DecodeRuneInString(name)
+
+			matched, rest, ok := globMatchClass(pattern[1:], r)
+			if !ok {
+				// 无效的字符类按普通字符处理
+				// an invalid class is a literal '['
+				if name[0] != '[' {
+					return false
+				}
+
+				pattern, name = pattern[1:], name[1:]
+				continue
+			}
+
+			if !matched {
+				return false
+			}
+
+			pattern, name = rest, name[size:]
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+
+			pr, psize := utf8.DecodeRuneInString(pattern)
+			nr, nsize := utf8.DecodeRuneInString(name)
+			if len(name) == 0 || pr != nr {
+				return false
+			}
+
+			pattern, name = pattern[psize:], name[nsize:]
+		}
+	}
+
+	return len(name) == 0
+}
+
+// 匹配字符类
+// match a character class, pattern is after the '['
+func globMatchClass(pattern string, r rune) (matched bool, rest string, ok bool) {
+	negate := false
+	if len(pattern) > 0 && (pattern[0] == '!' || pattern[0] == '^') {
+		negate = true
+		pattern = pattern[1:]
+	}
+
+	first := true
+	for len(pattern) > 0 {
+		if pattern[0] == ']' && !first {
+			return matched != negate, pattern[1:], true
+		}
+
+		first = false
+
+		lo, size := utf8.DecodeRuneInString(pattern)
+		pattern = pattern[size:]
+
+		hi := lo
+		if len(pattern) > 1 && pattern[0] == '-' && pattern[1] != ']' {
+			hi, size = utf8.DecodeRuneInString(pattern[1:])
+			pattern = pattern[1+size:]
+		}
+
+		if lo <= r && r <= hi {
+			matched = true
+		}
+	}
+
+	return false, "", false
+}
@@ -0,0 +1,171 @@
+package array
+
+import (
+	"testing"
+)
+
+func newWildcardData() map[string]any {
+	return map[string]any{
+		"users": []any{
+			map[string]any{"name": "u1", "email": "u1@example.com"},
+			map[string]any{"name": "u2", "email": "u2@example.com"},
+			map[string]any{"name": "u3"},
+		},
+		"servers": map[string]any{
+			"prod-1": map[string]any{"host": "p1.host"},
+			"prod-2": map[string]any{"host": "p2.host"},
+			"dev":    map[string]any{"host": "d.host"},
+		},
+		"typed": map[int]any{
+			1: map[string]any{"email": "t1@example.com"},
+		},
+		"a.b": map[string]any{
+			"email": "dot@example.com",
+		},
+	}
+}
+
+func Test_GetAll(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	data := newWildcardData()
+
+	testData := []struct {
+		key   string
+		check map[string]any
+	}{
+		{
+			"users.*.email",
+			map[string]any{
+				"users.0.email": "u1@example.com",
+				"users.1.email": "u2@example.com",
+			},
+		},
+		{
+			"servers.prod-*.host",
+			map[string]any{
+				"servers.prod-1.host": "p1.host",
+				"servers.prod-2.host": "p2.host",
+			},
+		},
+		{
+			"servers.prod-[!1].host",
+			map[string]any{
+				"servers.prod-2.host": "p2.host",
+			},
+		},
+		{
+			"servers.?e?.host",
+			map[string]any{
+				"servers.dev.host": "d.host",
+			},
+		},
+		{
+			"**.email",
+			map[string]any{
+				"users.0.email": "u1@example.com",
+				"users.1.email": "u2@example.com",
+				"typed.1.email": "t1@example.com",
+				"a~1b.email":    "dot@example.com",
+			},
+		},
+		{
+			"servers.**",
+			map[string]any{
+				"servers":             data["servers"],
+				"servers.prod-1":      data["servers"].(map[string]any)["prod-1"],
+				"servers.prod-2":      data["servers"].(map[string]any)["prod-2"],
+				"servers.dev":         data["servers"].(map[string]any)["dev"],
+				"servers.prod-1.host": "p1.host",
+				"servers.prod-2.host": "p2.host",
+				"servers.dev.host":    "d.host",
+			},
+		},
+		{
+			"nothing.*",
+			map[string]any{},
+		},
+	}
+
+	for _, v := range testData {
+		t.Run(v.key, func(t *testing.T) {
+			assert(GetAll(data, v.key), v.check, "GetAll "+v.key)
+		})
+	}
+
+	res := SubAll(data, "users.*.name")
+	assert(len(res), 3, "SubAll len")
+	assert(res[2].Value(), "u3", "SubAll value")
+}
+
+func Test_GetAllCycle(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	n := newTestStructCycle()
+
+	assert(GetAll(n, "**.name"), map[string]any{"name": "n"}, "GetAll cycle")
+	assert(GetAll(n, "**.**.name"), map[string]any{"name": "n"}, "GetAll repeated depth")
+}
+
+func Test_SetKeyAll(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr := New(newWildcardData())
+
+	res, err := arr.SetKeyAll("hidden", "users.*.email")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(len(res), 2, "SetKeyAll len")
+	assert(arr.Get("users.0.email"), "hidden", "SetKeyAll 0")
+	assert(arr.Get("users.1.email"), "hidden", "SetKeyAll 1")
+	assert(arr.Get("users.2.email"), nil, "SetKeyAll 2")
+}
+
+func Test_DeleteKeyAll(t *testing.T) {
+	assert := assertDeepEqualT(t)
+
+	arr := New(newWildcardData())
+
+	if err := arr.DeleteKeyAll("servers.prod-*"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(len(arr.Sub("servers").ChildrenMap()), 1, "DeleteKeyAll map")
+
+	if err := arr.DeleteKeyAll("users.*"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert(arr.Get("users"), []any{}, "DeleteKeyAll slice")
+}
+
+func Test_globMatch(t *testing.T) {
+	testData := []struct {
+		pattern string
+		name    string
+		check   bool
+	}{
+		{"*", "", true},
+		{"prod-*", "prod-1", true},
+		{"prod-*", "dev", false},
+		{"*-1", "prod-1", true},
+		{"p?od", "prod", true},
+		{"p?od", "pod", false},
+		{"[a-c]x", "bx", true},
+		{"[!a-c]x", "bx", false},
+		{"[^a-c]x", "dx", true},
+		{`\*`, "*", true},
+		{`\*`, "a", false},
+		{"a/*", "a/b", true},
+		{"[", "[", true},
+		{"中*", "中文", true},
+	}
+
+	for _, v := range testData {
+		if check := globMatch(v.pattern, v.name); check != v.check {
+			t.Errorf("globMatch(%q, %q) got %v, want %v", v.pattern, v.name, check, v.check)
+		}
+	}
+}